@@ -5,23 +5,26 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/kliuchnikovv/engi"
 	"github.com/kliuchnikovv/engi/definition/response"
 	"github.com/kliuchnikovv/packulator/internal/api"
 	"github.com/kliuchnikovv/packulator/internal/config"
+	"github.com/kliuchnikovv/packulator/internal/service"
 	"github.com/kliuchnikovv/packulator/internal/store"
 	"go.opentelemetry.io/otel"
-	"gorm.io/driver/postgres"
 )
 
 // main is the application entry point that initializes configuration,
 // sets up logging, establishes database connection, starts the HTTP server,
-// and handles graceful shutdown.
+// and handles graceful shutdown. Run as "packulator migrate" it migrates
+// the database instead, see migrate.
 func main() {
 	// Load application configuration from environment variables
 	cfg, err := config.NewAppConfig()
@@ -51,14 +54,24 @@ func main() {
 		logger     = slog.New(logHandler)
 	)
 
+	// Run database migrations instead of the server when asked to
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(context.Background(), &cfg.Database, os.Args[2:], logger); err != nil {
+			logger.Error("failed to migrate database", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.Info("starting packulator application",
 		"environment", cfg.App.Environment,
 		"address", cfg.ServerAddress(),
 		"debug", cfg.App.Debug,
+		"database", cfg.Database.Driver,
 	)
 
-	// Initialize database store with PostgreSQL connection
-	store, err := store.NewStore(postgres.Open(cfg.Database.DSN()))
+	// Initialize store with the configured database driver
+	store, err := store.Open(&cfg.Database)
 	if err != nil {
 		logger.Error("failed to create store", "error", err)
 		os.Exit(1)
@@ -75,12 +88,34 @@ func main() {
 		engi.WithTracerProvider(otel.GetTracerProvider()),
 	)
 
-	// Register API services: pack management, packaging calculations, and health checks
-	if err := engine.RegisterServices(
+	// Tables are shared by calculations of single packs and packaging hierarchies,
+	// every calculation is limited by the time budget and input limits
+	var calculation = []service.Option{
+		service.WithCache(service.NewTableCache(cfg.Calculator.CacheSize)),
+		service.WithBudget(cfg.Calculator.Budget),
+		service.WithInputLimits(service.InputLimits{
+			MaxAmount:    cfg.Calculator.MaxAmount,
+			MaxPackSizes: cfg.Calculator.MaxPackSizes,
+			MaxMemory:    cfg.Calculator.MaxMemory,
+		}),
+	}
+
+	// Register API services: pack management, packaging calculations, hierarchies, and health checks
+	var services = []engi.ServiceDefinition{
 		api.NewPacksAPI(store),
-		api.NewPackagingService(store),
+		api.NewPackagingService(store, calculation...),
+		api.NewHierarchiesAPI(store, calculation...),
 		api.NewHealthAPI(store),
-	); err != nil {
+	}
+
+	// Administration endpoints are only served when their key is configured
+	if cfg.Admin.APIKey != "" {
+		services = append(services, api.NewAdminAPI(store, cfg.Admin.APIKey, cfg.Admin.Retention))
+	} else {
+		logger.Info("administration endpoints are disabled, set ADMIN_API_KEY to enable them")
+	}
+
+	if err := engine.RegisterServices(services...); err != nil {
 		logger.Error("failed to register services", "error", err)
 		os.Exit(1)
 	}
@@ -104,3 +139,49 @@ func main() {
 	logger.Info("received interruption signal: shutting down")
 	engine.Shutdown(context.TODO())
 }
+
+// migrate applies or rolls back versioned database migrations by the command in args:
+// "up" (default) applies pending migrations, "down [steps]" rolls back the latest
+// applied ones, one by default, and "status" lists all migrations.
+func migrate(ctx context.Context, cfg *config.DatabaseConfig, args []string, logger *slog.Logger) error {
+	var command = "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	migrator, err := store.OpenMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			logger.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		return err
+	case "down":
+		var steps = 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of migrations to roll back: %q", args[1])
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, migration := range rolledBack {
+			logger.Info("rolled back migration", "version", migration.Version, "name", migration.Name)
+		}
+		return err
+	case "status":
+		migrations, err := migrator.Status(ctx)
+		for _, migration := range migrations {
+			logger.Info("migration", "version", migration.Version, "name", migration.Name, "applied", migration.Applied)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kliuchnikovv/engi"
+	"github.com/kliuchnikovv/engi/definition/middlewares/auth"
+	"github.com/kliuchnikovv/engi/definition/middlewares/cors"
+	"github.com/kliuchnikovv/engi/definition/parameter/placing"
+	"github.com/kliuchnikovv/engi/definition/parameter/query"
+	"github.com/kliuchnikovv/engi/definition/validate"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/service"
+	"github.com/kliuchnikovv/packulator/internal/store"
+)
+
+// AdminKeyHeader is the header carrying the key authorizing administration requests.
+const AdminKeyHeader = "X-Admin-Key"
+
+// maxRetentionDays is the longest retention period of purge requests.
+const maxRetentionDays = 36500
+
+// AdminAPI provides administration endpoints for deleted pack configurations:
+// listing them, restoring them and purging old ones permanently.
+type AdminAPI struct {
+	packService service.PackService // Service layer for pack operations
+	apiKey      string              // Key authorizing requests
+	retention   time.Duration       // Default period deleted packs are kept before they are purged
+}
+
+// NewAdminAPI creates a new administration API instance with the given store.
+// Requests are authorized by the key in the AdminKeyHeader header and deleted packs
+// are kept for the retention period unless purge requests set another one.
+func NewAdminAPI(store store.Store, apiKey string, retention time.Duration) *AdminAPI {
+	return &AdminAPI{
+		packService: service.NewPackService(store),
+		apiKey:      apiKey,
+		retention:   retention,
+	}
+}
+
+// Prefix returns the URL prefix for all administration endpoints.
+func (c *AdminAPI) Prefix() string {
+	return "admin"
+}
+
+// Middlewares returns the middleware stack for administration endpoints.
+// Allows all origins, headers, methods and requires the administration key.
+func (c *AdminAPI) Middlewares() []engi.Middleware {
+	return []engi.Middleware{
+		cors.AllowedOrigins("*"),
+		cors.AllowedHeaders("*"),
+		cors.AllowedMethods("*"),
+		auth.APIKey(AdminKeyHeader, c.apiKey, placing.InHeader),
+	}
+}
+
+// Routers defines the available administration routes:
+// GET /admin/trash - List deleted packs
+// POST /admin/restore - Restore deleted pack by ID
+// DELETE /admin/purge - Permanently delete packs deleted before the retention period
+func (c *AdminAPI) Routers() engi.Routes {
+	return engi.Routes{
+		engi.GET("trash"): engi.Handle(c.ListDeletedPacks),
+		engi.PST("restore"): engi.Handle(
+			c.RestorePack,
+			query.String("id", validate.NotEmpty),
+		),
+		engi.DEL("purge"): engi.Handle(c.PurgePacks),
+	}
+}
+
+// ListDeletedPacks handles GET /admin/trash requests.
+// It returns deleted pack configurations, the most recently deleted first.
+func (c *AdminAPI) ListDeletedPacks(
+	ctx context.Context,
+	_ engi.Request,
+	response engi.Response,
+) error {
+	packs, err := c.packService.ListDeletedPacks(ctx)
+	if err != nil {
+		return response.InternalServerError("can't list deleted packs: %s", err)
+	}
+
+	var deleted = make([]model.DeletedPack, len(packs))
+	for i, pack := range packs {
+		deleted[i] = model.DeletedPack{Pack: pack, DeletedAt: pack.DeletedAt.Time}
+	}
+
+	return response.OK(model.ListDeletedPacksResponse{
+		Packs: deleted,
+	})
+}
+
+// RestorePack handles POST /admin/restore requests.
+// It restores a deleted pack configuration by its unique ID unless an active one
+// has the same version hash.
+func (c *AdminAPI) RestorePack(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	var id = request.String("id", placing.InQuery)
+
+	pack, err := c.packService.RestorePack(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrNotFound):
+		return response.NotFound("deleted pack not found by id: %s", id)
+	case errors.Is(err, store.ErrConflict):
+		return response.Errorf(http.StatusConflict, "can't restore pack: %s", err)
+	default:
+		return response.InternalServerError("can't restore pack: %s", err)
+	}
+
+	return response.OK(pack)
+}
+
+// PurgePacks handles DELETE /admin/purge requests.
+// It permanently deletes pack configurations with their items deleted before the retention
+// period, optionally set in days by the retention_days parameter.
+func (c *AdminAPI) PurgePacks(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	retention, err := parseRetention(request.String("retention_days", placing.InQuery), c.retention)
+	if err != nil {
+		return response.BadRequest("invalid retention_days: %s", err)
+	}
+
+	var before = time.Now().Add(-retention)
+
+	purged, err := c.packService.PurgePacks(ctx, before)
+	if err != nil {
+		return response.InternalServerError("can't purge packs, %d purged: %s", purged, err)
+	}
+
+	return response.OK(model.PurgePacksResponse{
+		Purged:        purged,
+		DeletedBefore: before,
+	})
+}
+
+// parseRetention parses an optional retention period in days, fallback if it isn't set.
+func parseRetention(raw string, fallback time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 0 || days > maxRetentionDays {
+		return 0, fmt.Errorf("expected a number of days from 0 to %d, got %q", maxRetentionDays, raw)
+	}
+
+	return time.Duration(days) * 24 * time.Hour, nil
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/store"
+	mock_store "github.com/kliuchnikovv/packulator/internal/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestAdminAPI_ListDeletedPacks(t *testing.T) {
+	t.Run("successful listing", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		pack := model.Pack{
+			ID:          "pack-1",
+			VersionHash: "abc123",
+			DeletedAt:   gorm.DeletedAt{Time: deletedAt, Valid: true},
+		}
+
+		mockStore.EXPECT().ListDeletedPacks(gomock.Any()).Return([]model.Pack{pack}, nil)
+		response.On("OK", mock.Anything).Return(nil)
+
+		err := api.ListDeletedPacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+		assert.Equal(t, model.ListDeletedPacksResponse{
+			Packs: []model.DeletedPack{{Pack: pack, DeletedAt: deletedAt}},
+		}, response.data)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expectedError := errors.New("database error")
+		mockStore.EXPECT().ListDeletedPacks(gomock.Any()).Return(nil, expectedError)
+		response.On("InternalServerError", "can't list deleted packs: %s", mock.Anything).Return(expectedError)
+
+		err := api.ListDeletedPacks(ctx, request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 500, response.statusCode)
+
+		response.AssertExpectations(t)
+	})
+}
+
+func TestAdminAPI_RestorePack(t *testing.T) {
+	t.Run("successful restore", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		pack := &model.Pack{ID: "pack-1", VersionHash: "abc123"}
+
+		request.On("String", "id", mock.Anything).Return("pack-1")
+		mockStore.EXPECT().RestorePack(gomock.Any(), "pack-1").Return(pack, nil)
+		response.On("OK", pack).Return(nil)
+
+		err := api.RestorePack(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expectedError := errors.New("not found")
+
+		request.On("String", "id", mock.Anything).Return("missing")
+		mockStore.EXPECT().RestorePack(gomock.Any(), "missing").Return(nil, store.ErrNotFound)
+		response.On("NotFound", "deleted pack not found by id: %s", mock.Anything).Return(expectedError)
+
+		err := api.RestorePack(ctx, request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, 404, response.statusCode)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expectedError := errors.New("conflict")
+
+		request.On("String", "id", mock.Anything).Return("pack-1")
+		mockStore.EXPECT().RestorePack(gomock.Any(), "pack-1").Return(nil, store.ErrConflict)
+		response.On("Errorf", http.StatusConflict, "can't restore pack: %s", mock.Anything).Return(expectedError)
+
+		err := api.RestorePack(ctx, request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusConflict, response.statusCode)
+	})
+}
+
+func TestAdminAPI_PurgePacks(t *testing.T) {
+	t.Run("default retention", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", 30*24*time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "retention_days", mock.Anything).Return("")
+		mockStore.EXPECT().PurgePacks(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, before time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), before, time.Minute)
+				return 2, nil
+			},
+		)
+		response.On("OK", mock.Anything).Return(nil)
+
+		err := api.PurgePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+
+		data, ok := response.data.(model.PurgePacksResponse)
+		require.True(t, ok)
+		assert.Equal(t, int64(2), data.Purged)
+	})
+
+	t.Run("requested retention", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", 30*24*time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "retention_days", mock.Anything).Return("0")
+		mockStore.EXPECT().PurgePacks(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, before time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now(), before, time.Minute)
+				return 0, nil
+			},
+		)
+		response.On("OK", mock.Anything).Return(nil)
+
+		err := api.PurgePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+	})
+
+	t.Run("invalid retention", func(t *testing.T) {
+		for _, raw := range []string{"-1", "week", "36501"} {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewAdminAPI(mockStore, "secret", time.Hour)
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+
+			expectedError := errors.New("bad request")
+
+			request.On("String", "retention_days", mock.Anything).Return(raw)
+			response.On("BadRequest", "invalid retention_days: %s", mock.Anything).Return(expectedError)
+
+			err := api.PurgePacks(context.Background(), request, response)
+
+			assert.Equal(t, expectedError, err, raw)
+			assert.Equal(t, 400, response.statusCode, raw)
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewAdminAPI(mockStore, "secret", time.Hour)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expectedError := errors.New("database error")
+
+		request.On("String", "retention_days", mock.Anything).Return("")
+		mockStore.EXPECT().PurgePacks(gomock.Any(), gomock.Any()).Return(int64(500), expectedError)
+		response.On("InternalServerError", "can't purge packs, %d purged: %s", mock.Anything).Return(expectedError)
+
+		err := api.PurgePacks(ctx, request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 500, response.statusCode)
+
+		response.AssertExpectations(t)
+	})
+}
@@ -3,26 +3,57 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/kliuchnikovv/engi"
 	"github.com/kliuchnikovv/engi/definition/middlewares/auth"
 	"github.com/kliuchnikovv/engi/definition/middlewares/cors"
+	"github.com/kliuchnikovv/engi/definition/parameter"
 	"github.com/kliuchnikovv/engi/definition/parameter/placing"
 	"github.com/kliuchnikovv/engi/definition/parameter/query"
 	"github.com/kliuchnikovv/engi/definition/validate"
+	"github.com/kliuchnikovv/packulator/internal/model"
 	"github.com/kliuchnikovv/packulator/internal/service"
 	"github.com/kliuchnikovv/packulator/internal/store"
 )
 
+// fitHeader is the response header reporting how the shipped quantity relates to the amount.
+const fitHeader = "X-Quantity-Fit"
+
+// maxBatchItems is the largest number of orders accepted by a batch calculation.
+const maxBatchItems = 10_000
+
+// maxOrderLines is the largest number of products accepted in an order.
+const maxOrderLines = 1_000
+
+// Limits of runner-up combinations in explained calculations
+const (
+	defaultAlternatives = 3  // Number of alternatives if not set
+	maxAlternatives     = 10 // Largest number of alternatives
+)
+
+// batchWorkers bounds the number of pack configurations calculated concurrently in a batch.
+var batchWorkers = runtime.GOMAXPROCS(0)
+
 // PackagingService provides endpoints for pack calculation operations.
 type PackagingService struct {
-	store store.Store // Database store for pack retrieval
+	store   store.Store      // Database store for pack retrieval
+	options []service.Option // Options applied to every calculation
 }
 
 // NewPackagingService creates a new packaging service instance with the given store.
-func NewPackagingService(store store.Store) *PackagingService {
+// Provided options, such as a table cache, are applied to every calculation.
+func NewPackagingService(store store.Store, opts ...service.Option) *PackagingService {
 	return &PackagingService{
-		store: store,
+		store:   store,
+		options: opts,
 	}
 }
 
@@ -44,6 +75,31 @@ func (c *PackagingService) Middlewares() []engi.Middleware {
 
 // Routers defines the available packaging calculation routes:
 // GET /packaging/number_of_packages - Calculate optimal pack combination for given amount
+// POST /packaging/batch_number_of_packages - Calculate pack combinations for many orders
+// POST /packaging/order - Calculate a consolidated shipment of an order of several products
+//
+// Optional query parameters:
+//   - stock - available packs by size, e.g. "5000:3,2000:10"; absent sizes are unlimited
+//   - strategy - what to optimize: "least_overshoot" (default), "fewest_packs", "cheapest" by pack costs,
+//     "within_packs:N" for the least overshoot with at most N packs or "lexicographic:cost,packs,..."
+//     for a custom priority of "overshoot", "packs" and "cost"
+//   - objective - former name of strategy, accepted as its alias
+//   - tolerance_under - allowed shortfall in items or percent of the amount, e.g. "20" or "2%"
+//   - tolerance_over - allowed excess not counted as overshoot, in items or percent of the amount
+//   - explain - "true" to respond with measures of the chosen combination and runner-up combinations
+//   - alternatives - number of runner-up combinations in explained results, 3 by default
+//   - legacy - "true" to respond with a bare map of pack counts by size instead of model.CalculationResponse
+//   - max_weight, max_volume - limits of a single shipment to split packs into shipments by weights
+//     and volumes of packs; not combined with legacy or explain
+//   - max_packs_per_parcel - limit of packs in a parcel to split packs into evenly balanced parcels;
+//     not combined with legacy, explain or shipment limits
+//   - max_packs - largest total number of packs; if no combination fits, responds with
+//     model.InfeasibleResponse and the fewest packs needed
+//   - pareto - "true" to respond with all combinations not dominated by overshoot and number of packs;
+//     the strategy only bounds them, e.g. "within_packs:N"; not combined with other response modes
+//
+// The X-Quantity-Fit response header reports whether the shipped quantity is
+// "under", "exact" or "over" the amount.
 func (c *PackagingService) Routers() engi.Routes {
 	return engi.Routes{
 		engi.GET("number_of_packages"): engi.Handle(
@@ -51,6 +107,14 @@ func (c *PackagingService) Routers() engi.Routes {
 			query.Integer("amount", validate.Greater(0)),  // Required: amount > 0
 			query.String("packs_hash", validate.NotEmpty), // Required: pack configuration hash
 		),
+		engi.PST("batch_number_of_packages"): engi.Handle(
+			c.BatchNumberOfPackages,
+			parameter.Body(new(model.BatchCalculationRequest)),
+		),
+		engi.PST("order"): engi.Handle(
+			c.PackOrder,
+			parameter.Body(new(model.OrderRequest)),
+		),
 	}
 }
 
@@ -66,8 +130,106 @@ func (c *PackagingService) NumberOfPackages(
 	var (
 		amount      = request.Integer("amount", placing.InQuery)    // Amount to be packed
 		versionHash = request.String("packs_hash", placing.InQuery) // Pack configuration hash
+		options     = slices.Clone(c.options)
 	)
 
+	// Parse optional stock limits
+	stock, err := parseStock(request.String("stock", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid stock: %s", err)
+	}
+
+	if stock != nil {
+		options = append(options, service.WithStock(stock))
+	}
+
+	// Parse optional calculation strategy, objective is its former name kept as an alias
+	var rawStrategy = request.String("strategy", placing.InQuery)
+	if objective := request.String("objective", placing.InQuery); objective != "" {
+		if rawStrategy != "" && rawStrategy != objective {
+			return response.BadRequest("objective %q conflicts with strategy %q", objective, rawStrategy)
+		}
+
+		rawStrategy = objective
+	}
+
+	strategy, err := service.ParseStrategy(rawStrategy)
+	if err != nil {
+		return response.BadRequest("invalid strategy: %s", err)
+	}
+
+	// Parse optional tolerances
+	under, err := parseTolerance(request.String("tolerance_under", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid tolerance_under: %s", err)
+	}
+
+	over, err := parseTolerance(request.String("tolerance_over", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid tolerance_over: %s", err)
+	}
+
+	options = append(options, service.WithTolerance(under, over))
+
+	// Parse optional limit of packs
+	maxPacks, err := parseLimit(request.String("max_packs", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid max_packs: %s", err)
+	}
+
+	if maxPacks > 0 {
+		options = append(options, service.WithMaxPacks(maxPacks))
+	}
+
+	// Parse optional response format
+	legacy, err := parseFlag(request.String("legacy", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid legacy flag: %s", err)
+	}
+
+	// Parse optional explanation settings
+	explain, alternatives, err := parseExplain(
+		request.String("explain", placing.InQuery),
+		request.String("alternatives", placing.InQuery),
+	)
+	if err != nil {
+		return response.BadRequest("invalid explanation: %s", err)
+	}
+
+	// Parse optional shipment limits
+	limits, err := parseLimits(
+		request.String("max_weight", placing.InQuery),
+		request.String("max_volume", placing.InQuery),
+	)
+	if err != nil {
+		return response.BadRequest("invalid shipment limits: %s", err)
+	}
+
+	var split = limits != service.Limits{}
+	if split && (legacy || explain) {
+		return response.BadRequest("shipment limits can't be combined with legacy or explain")
+	}
+
+	// Parse optional parcel limit
+	maxPacksPerParcel, err := parseLimit(request.String("max_packs_per_parcel", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid max_packs_per_parcel: %s", err)
+	}
+
+	if maxPacksPerParcel > 0 && (legacy || explain || split) {
+		return response.BadRequest("max_packs_per_parcel can't be combined with legacy, explain or shipment limits")
+	}
+
+	// Parse optional Pareto front mode
+	pareto, err := parseFlag(request.String("pareto", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid pareto flag: %s", err)
+	}
+
+	if pareto && (legacy || explain || split || maxPacksPerParcel > 0) {
+		return response.BadRequest("pareto can't be combined with legacy, explain, shipment limits or parcels")
+	}
+
 	// Retrieve pack configuration by hash
 	pack, err := c.store.GetPackByHash(ctx, versionHash)
 	switch {
@@ -80,10 +242,503 @@ func (c *PackagingService) NumberOfPackages(
 	}
 
 	// Calculate optimal pack combination
-	result, err := service.NumberOfPacks(ctx, amount, pack.GetPacks())
+	options = append(options,
+		service.WithStrategy(strategy),
+		service.WithCosts(pack.GetCosts()),
+		service.WithRules(service.PackRules(pack)),
+	)
+
+	// Respond with trade-offs between overshoot and number of packs if requested
+	if pareto {
+		front, err := service.ParetoFront(ctx, amount, pack.GetPacks(), options...)
+		if err != nil {
+			return calculationError(response, err)
+		}
+
+		return response.OK(newCalculationParetoFront(amount, versionHash, front))
+	}
+
+	// Explain the chosen combination with runner-up ones if requested
+	if explain {
+		explanation, err := service.ExplainNumberOfPacks(ctx, amount, pack.GetPacks(), alternatives, options...)
+		if err != nil {
+			return calculationError(response, err)
+		}
+
+		response.ResponseWriter().Header().Set(fitHeader, string(service.FitOf(amount, explanation.Chosen.Packs)))
+
+		return response.OK(newCalculationExplanation(explanation))
+	}
+
+	result, err := service.NumberOfPacks(ctx, amount, pack.GetPacks(), options...)
 	if err != nil {
+		return calculationError(response, err)
+	}
+
+	response.ResponseWriter().Header().Set(fitHeader, string(service.FitOf(amount, result)))
+
+	// Respond with the shape existing clients rely on if requested
+	if legacy {
+		return response.OK(result)
+	}
+
+	var calculation = model.NewCalculationResponse(amount, versionHash, strategy.Name(), result)
+
+	// Split packs into shipments within limits if requested
+	if split {
+		shipments, err := service.SplitShipments(result, packMeasures(pack), limits)
+		if err != nil {
+			return calculationError(response, err)
+		}
+
+		calculation.Shipments = newShipments(shipments)
+	}
+
+	// Group packs into parcels if requested
+	if maxPacksPerParcel > 0 {
+		parcels, err := service.SplitParcels(result, maxPacksPerParcel)
+		if err != nil {
+			return response.InternalServerError("can't split parcels: %s", err)
+		}
+
+		calculation.Parcels = newParcels(parcels)
+	}
+
+	return response.OK(calculation)
+}
+
+// PackOrder handles POST /packaging/order requests.
+// It calculates every product of the order with its own pack configuration, fetching
+// each configuration once, and responds with the per-line breakdown and packs of the whole
+// order by size. The order fails if any of its lines can't be calculated.
+func (c *PackagingService) PackOrder(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	body, ok := request.Body().(*model.OrderRequest)
+	if !ok || len(body.Lines) == 0 {
+		return response.BadRequest("lines can't be empty")
+	}
+
+	if len(body.Lines) > maxOrderLines {
+		return response.BadRequest("too many lines: %d (max %d)", len(body.Lines), maxOrderLines)
+	}
+
+	for i, line := range body.Lines {
+		switch {
+		case line.Amount <= 0:
+			return response.BadRequest("line %d (%s): amount must be greater than 0", i, line.SKU)
+		case line.PacksHash == "":
+			return response.BadRequest("line %d (%s): packs_hash can't be empty", i, line.SKU)
+		}
+	}
+
+	var (
+		packs    = make(map[string]*model.Pack)
+		strategy = service.LeastOvershoot()
+		results  = make([]model.OrderLineResult, len(body.Lines))
+	)
+
+	for i, line := range body.Lines {
+		// Retrieve pack configuration by hash unless another line already did
+		pack, ok := packs[line.PacksHash]
+		if !ok {
+			var err error
+
+			pack, err = c.store.GetPackByHash(ctx, line.PacksHash)
+			switch {
+			case err == nil:
+				packs[line.PacksHash] = pack
+			case errors.Is(err, store.ErrNotFound):
+				return response.NotFound("line %d (%s): packs not found by hash: %s", i, line.SKU, line.PacksHash)
+			default:
+				return response.InternalServerError("failed to get packs: %s", err)
+			}
+		}
+
+		var options = append(slices.Clone(c.options),
+			service.WithStrategy(strategy),
+			service.WithCosts(pack.GetCosts()),
+			service.WithRules(service.PackRules(pack)),
+		)
+
+		combination, err := service.NumberOfPacks(ctx, line.Amount, pack.GetPacks(), options...)
+		if err != nil {
+			return calculationError(response, fmt.Errorf("line %d (%s): %w", i, line.SKU, err))
+		}
+
+		results[i] = model.OrderLineResult{
+			SKU:                 line.SKU,
+			CalculationResponse: model.NewCalculationResponse(line.Amount, line.PacksHash, strategy.Name(), combination),
+		}
+	}
+
+	return response.OK(model.NewOrderResponse(results))
+}
+
+// calculationError responds with the status matching the calculation error.
+// Amounts over input limits and options that can't be combined are bad requests, orders
+// that can't be satisfied and pack configurations that can't be calculated are unprocessable,
+// other errors are internal.
+// Infeasible limits of packs are reported with the fewest packs needed. Calculations
+// running out of the time budget are unavailable and ones canceled by clients time out.
+func calculationError(response engi.Response, err error) error {
+	var infeasible *service.InfeasibleError
+
+	switch {
+	case errors.Is(err, service.ErrTimeout) && errors.Is(err, context.DeadlineExceeded):
+		return response.Errorf(http.StatusServiceUnavailable, "can't calculate number of packages in time: %s", err)
+	case errors.Is(err, service.ErrTimeout):
+		return response.Errorf(http.StatusRequestTimeout, "can't calculate number of packages: %s", err)
+	case errors.As(err, &infeasible):
+		return response.Object(http.StatusUnprocessableEntity, model.InfeasibleResponse{
+			Error:    fmt.Sprintf("can't calculate number of packages: %s", err),
+			MaxPacks: infeasible.MaxPacks,
+			MinPacks: infeasible.MinPacks,
+		})
+	case errors.Is(err, service.ErrAmountTooLarge),
+		errors.Is(err, service.ErrUnsupportedOptions):
+		return response.BadRequest("can't calculate number of packages: %s", err)
+	case errors.Is(err, service.ErrInsufficientStock),
+		errors.Is(err, service.ErrNoCombination),
+		errors.Is(err, service.ErrPackOverLimits),
+		errors.Is(err, service.ErrInvalidPackSize),
+		errors.Is(err, service.ErrTooManyPackSizes):
+		return response.Errorf(http.StatusUnprocessableEntity, "can't calculate number of packages: %s", err)
+	default:
 		return response.InternalServerError("can't calculate number of packages: %s", err)
 	}
+}
+
+// newCalculationExplanation converts the explanation into its DTO.
+func newCalculationExplanation(explanation *service.Explanation) model.CalculationExplanation {
+	var result = model.CalculationExplanation{
+		Strategy:     explanation.Strategy,
+		Chosen:       newCalculationCandidate(explanation.Chosen),
+		Alternatives: make([]model.CalculationCandidate, len(explanation.Alternatives)),
+	}
+
+	for i, alternative := range explanation.Alternatives {
+		result.Alternatives[i] = newCalculationCandidate(alternative)
+	}
+
+	return result
+}
+
+// newCalculationParetoFront converts the Pareto front calculated for the amount into its DTO.
+func newCalculationParetoFront(amount int64, versionHash string, front []service.Candidate) model.CalculationParetoFront {
+	var result = model.CalculationParetoFront{
+		RequestedAmount: amount,
+		VersionHash:     versionHash,
+		Front:           make([]model.CalculationCandidate, len(front)),
+	}
+
+	for i, candidate := range front {
+		result.Front[i] = newCalculationCandidate(candidate)
+	}
+
+	return result
+}
+
+// newCalculationCandidate converts the candidate into its DTO.
+func newCalculationCandidate(candidate service.Candidate) model.CalculationCandidate {
+	return model.CalculationCandidate{
+		Packs:         candidate.Packs,
+		Shipped:       candidate.Shipped,
+		Overshoot:     candidate.Overshoot,
+		NumberOfPacks: candidate.Count,
+		Cost:          candidate.Cost,
+	}
+}
+
+// BatchNumberOfPackages handles POST /packaging/batch_number_of_packages requests.
+// It calculates optimal combinations for a list of orders, grouping them by pack configuration
+// so every configuration is fetched and calculated once for all of its amounts. Groups are
+// calculated concurrently by a bounded number of workers. Failed orders get an error in
+// their result without failing the whole batch.
+func (c *PackagingService) BatchNumberOfPackages(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	body, ok := request.Body().(*model.BatchCalculationRequest)
+	if !ok || len(body.Items) == 0 {
+		return response.BadRequest("items can't be empty")
+	}
+
+	if len(body.Items) > maxBatchItems {
+		return response.BadRequest("too many items: %d (max %d)", len(body.Items), maxBatchItems)
+	}
+
+	// Group valid orders by pack configuration keeping the order of first appearance
+	var (
+		results = make([]model.BatchCalculationResult, len(body.Items))
+		groups  = make(map[string][]int)
+		hashes  []string
+	)
+
+	for i, item := range body.Items {
+		results[i] = model.BatchCalculationResult{
+			Amount:    item.Amount,
+			PacksHash: item.PacksHash,
+		}
+
+		switch {
+		case item.Amount <= 0:
+			results[i].Error = "amount must be greater than 0"
+		case item.PacksHash == "":
+			results[i].Error = "packs_hash can't be empty"
+		default:
+			if _, ok := groups[item.PacksHash]; !ok {
+				hashes = append(hashes, item.PacksHash)
+			}
+
+			groups[item.PacksHash] = append(groups[item.PacksHash], i)
+		}
+	}
+
+	// Calculate groups with a bounded pool of workers, each writes only results of its group
+	var (
+		jobs = make(chan string)
+		wg   sync.WaitGroup
+	)
+
+	for range min(batchWorkers, len(hashes)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for hash := range jobs {
+				c.calculateBatchGroup(ctx, hash, groups[hash], results)
+			}
+		}()
+	}
+
+	for _, hash := range hashes {
+		jobs <- hash
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return response.OK(model.BatchCalculationResponse{Results: results})
+}
+
+// calculateBatchGroup calculates orders of a batch at the indexes sharing the pack configuration
+// and stores their outcomes into results.
+func (c *PackagingService) calculateBatchGroup(
+	ctx context.Context,
+	versionHash string,
+	indexes []int,
+	results []model.BatchCalculationResult,
+) {
+	pack, err := c.store.GetPackByHash(ctx, versionHash)
+	if err != nil {
+		var message = fmt.Sprintf("failed to get packs: %s", err)
+		if errors.Is(err, store.ErrNotFound) {
+			message = fmt.Sprintf("packs not found by hash: %s", versionHash)
+		}
+
+		for _, i := range indexes {
+			results[i].Error = message
+		}
+
+		return
+	}
+
+	var amounts = make([]int64, len(indexes))
+	for j, i := range indexes {
+		amounts[j] = results[i].Amount
+	}
+
+	var options = append(slices.Clone(c.options),
+		service.WithCosts(pack.GetCosts()),
+		service.WithRules(service.PackRules(pack)),
+	)
+
+	combinations, errs := service.NumberOfPacksBatch(ctx, amounts, pack.GetPacks(), options...)
+	for j, i := range indexes {
+		if errs[j] != nil {
+			results[i].Error = fmt.Sprintf("can't calculate number of packages: %s", errs[j])
+			continue
+		}
+
+		results[i].Packs = combinations[j]
+	}
+}
+
+// packMeasures returns weights and volumes of packs of the configuration by size.
+func packMeasures(pack *model.Pack) map[int64]service.Measures {
+	var measures = make(map[int64]service.Measures, len(pack.PackItems))
+	for _, item := range pack.PackItems {
+		measures[item.Size] = service.Measures{Weight: item.Weight, Volume: item.Volume}
+	}
+
+	return measures
+}
+
+// newShipments converts shipments into their DTOs.
+func newShipments(shipments []service.Shipment) []model.Shipment {
+	var result = make([]model.Shipment, len(shipments))
+	for i, shipment := range shipments {
+		result[i] = model.Shipment{
+			Lines:  model.NewPackLines(shipment.Packs),
+			Weight: shipment.Weight,
+			Volume: shipment.Volume,
+			Count:  shipment.Count,
+		}
+	}
+
+	return result
+}
+
+// newParcels converts parcels into their DTOs.
+func newParcels(parcels []service.Parcel) []model.Parcel {
+	var result = make([]model.Parcel, len(parcels))
+	for i, parcel := range parcels {
+		result[i] = model.Parcel{
+			Lines: model.NewPackLines(parcel.Packs),
+			First: parcel.First,
+			Count: parcel.Count,
+		}
+
+		for _, count := range parcel.Packs {
+			result[i].Packs += count
+		}
+	}
+
+	return result
+}
+
+// parseStock parses stock limits formatted as comma-separated "size:count" pairs.
+// Returns nil if no limits are set.
+func parseStock(raw string) (map[int64]int64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var stock = make(map[int64]int64)
+
+	for _, pair := range strings.Split(raw, ",") {
+		size, count, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("expected 'size:count', got %q", pair)
+		}
+
+		parsedSize, err := strconv.ParseInt(size, 10, 64)
+		if err != nil || parsedSize <= 0 {
+			return nil, fmt.Errorf("invalid pack size %q", size)
+		}
+
+		parsedCount, err := strconv.ParseInt(count, 10, 64)
+		if err != nil || parsedCount < 0 {
+			return nil, fmt.Errorf("invalid count %q of pack %d", count, parsedSize)
+		}
+
+		stock[parsedSize] = parsedCount
+	}
+
+	return stock, nil
+}
+
+// parseExplain parses whether the calculation is explained and the number of
+// runner-up combinations to report, defaultAlternatives if it isn't set.
+func parseExplain(rawExplain, rawAlternatives string) (bool, int, error) {
+	explain, err := parseFlag(rawExplain)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid explain flag: %w", err)
+	}
+
+	if rawAlternatives == "" {
+		return explain, defaultAlternatives, nil
+	}
+
+	alternatives, err := strconv.Atoi(rawAlternatives)
+	if err != nil || alternatives < 0 || alternatives > maxAlternatives {
+		return false, 0, fmt.Errorf("alternatives must be from 0 to %d, got %q", maxAlternatives, rawAlternatives)
+	}
+
+	return explain, alternatives, nil
+}
+
+// parseFlag parses an optional boolean flag, false if it isn't set.
+func parseFlag(raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+
+	flag, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("expected a boolean, got %q", raw)
+	}
+
+	return flag, nil
+}
+
+// parseLimits parses optional limits of the weight and the volume of a shipment.
+// Returns zero limits if none is set.
+func parseLimits(rawWeight, rawVolume string) (service.Limits, error) {
+	var limits service.Limits
+
+	for _, limit := range []struct {
+		name  string
+		raw   string
+		value *int64
+	}{
+		{name: "max_weight", raw: rawWeight, value: &limits.Weight},
+		{name: "max_volume", raw: rawVolume, value: &limits.Volume},
+	} {
+		if limit.raw == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(limit.raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return service.Limits{}, fmt.Errorf("%s must be a positive integer, got %q", limit.name, limit.raw)
+		}
+
+		*limit.value = parsed
+	}
+
+	return limits, nil
+}
+
+// parseLimit parses an optional positive limit of packs, zero if it isn't set.
+func parseLimit(raw string) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("expected a positive integer, got %q", raw)
+	}
+
+	return parsed, nil
+}
+
+// parseTolerance parses a tolerance formatted as a number of items or a percent
+// of the amount ending with "%". Returns zero tolerance if it isn't set.
+func parseTolerance(raw string) (service.Tolerance, error) {
+	if raw == "" {
+		return service.Tolerance{}, nil
+	}
+
+	if percent, ok := strings.CutSuffix(raw, "%"); ok {
+		parsed, err := strconv.ParseFloat(percent, 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) || parsed < 0 {
+			return service.Tolerance{}, fmt.Errorf("invalid percent %q", raw)
+		}
+
+		return service.Tolerance{Percent: parsed}, nil
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || parsed < 0 {
+		return service.Tolerance{}, fmt.Errorf("invalid number of items %q", raw)
+	}
 
-	return response.OK(result)
+	return service.Tolerance{Items: parsed}, nil
 }
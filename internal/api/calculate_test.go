@@ -3,9 +3,13 @@ package api
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/service"
 	"github.com/kliuchnikovv/packulator/internal/store"
 	mock_store "github.com/kliuchnikovv/packulator/internal/store/mocks"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +26,13 @@ func TestNewPackagingService(t *testing.T) {
 	assert.Equal(t, mockStore, api.store)
 }
 
+func TestNewPackagingService_WithOptions(t *testing.T) {
+	mockStore := mock_store.NewMockStore(gomock.NewController(t))
+	api := NewPackagingService(mockStore, service.WithCache(service.NewTableCache(1<<20)))
+
+	assert.Len(t, api.options, 1)
+}
+
 func TestPackagingService_Prefix(t *testing.T) {
 	mockStore := mock_store.NewMockStore(gomock.NewController(t))
 	api := NewPackagingService(mockStore)
@@ -56,12 +67,14 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 		// Mock request parameters
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		// Mock store response
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil)
 
 		// Mock response
-		response.On("OK", mock.AnythingOfType("map[int64]int64")).Return(nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
 
 		err := api.NumberOfPackages(ctx, request, response)
 
@@ -69,8 +82,7 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 		assert.Equal(t, 200, response.statusCode)
 
 		// Verify the response data is a valid pack calculation
-		responseData, ok := response.data.(map[int64]int64)
-		require.True(t, ok)
+		responseData := responsePacks(t, response)
 		assert.NotEmpty(t, responseData)
 
 		// Verify total amount is sufficient
@@ -97,6 +109,7 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(nil, store.ErrNotFound)
 
@@ -126,6 +139,7 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(nil, expectedError)
 
@@ -163,10 +177,12 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil)
 
-		response.On("OK", mock.AnythingOfType("map[int64]int64")).Return(nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
 
 		err := api.NumberOfPackages(ctx, request, response)
 
@@ -174,8 +190,7 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 		assert.Equal(t, 200, response.statusCode)
 
 		// Response should be empty map for zero amount
-		responseData, ok := response.data.(map[int64]int64)
-		require.True(t, ok)
+		responseData := responsePacks(t, response)
 		assert.Empty(t, responseData)
 
 		request.AssertExpectations(t)
@@ -205,10 +220,12 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil)
 
-		response.On("OK", mock.AnythingOfType("map[int64]int64")).Return(nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
 
 		err := api.NumberOfPackages(ctx, request, response)
 
@@ -216,8 +233,7 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 		assert.Equal(t, 200, response.statusCode)
 
 		// Verify response has packs that cover the amount
-		responseData, ok := response.data.(map[int64]int64)
-		require.True(t, ok)
+		responseData := responsePacks(t, response)
 		assert.NotEmpty(t, responseData)
 
 		total := int64(0)
@@ -232,6 +248,1428 @@ func TestPackagingService_NumberOfPackages(t *testing.T) {
 	})
 }
 
+func TestPackagingService_NumberOfPackagesResponseFormat(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		expected any
+	}{
+		{
+			name:   "structured",
+			values: map[string]string{"strategy": "fewest_packs"},
+			expected: model.CalculationResponse{
+				Lines:           []model.PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 1}},
+				RequestedAmount: 1001,
+				ShippedAmount:   1250,
+				Overshoot:       249,
+				TotalPacks:      2,
+				VersionHash:     "abc123",
+				Algorithm:       "fewest_packs",
+			},
+		},
+		{
+			name:     "legacy",
+			values:   map[string]string{"legacy": "true"},
+			expected: map[int64]int64{250: 1, 1000: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewPackagingService(mockStore)
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+
+			request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+			request.On("String", "packs_hash", mock.Anything).Return("abc123")
+			mockOptionalParameters(request, tt.values)
+
+			mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+			mockResponseWriter(response)
+			response.On("OK", mock.Anything).Return(nil)
+
+			err := api.NumberOfPackages(context.Background(), request, response)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, response.data)
+		})
+	}
+
+	t.Run("invalid legacy flag", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"legacy": "maybe"})
+
+		response.On("BadRequest", "invalid legacy flag: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesCached(t *testing.T) {
+	var (
+		mockStore   = mock_store.NewMockStore(gomock.NewController(t))
+		cache       = service.NewTableCache(1 << 20)
+		api         = NewPackagingService(mockStore, service.WithCache(cache))
+		versionHash = "abc123"
+		pack        = model.Pack{
+			ID:          "pack-1",
+			VersionHash: versionHash,
+			PackItems: []model.PackItem{
+				{ID: "item-1", PackID: "pack-1", Size: 23},
+				{ID: "item-2", PackID: "pack-1", Size: 31},
+				{ID: "item-3", PackID: "pack-1", Size: 53},
+			},
+		}
+	)
+
+	mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil).Times(2)
+
+	for _, amount := range []int64{500, 263} {
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(amount)
+		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		expected, err := service.NumberOfPacks(context.Background(), amount, pack.GetPacks())
+		require.NoError(t, err)
+		assert.Equal(t, expected, responsePacks(t, response))
+	}
+
+	assert.Equal(t, 1, cache.Len(), "both requests should share one table")
+}
+
+func TestPackagingService_NumberOfPackagesWithStock(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	t.Run("limited stock", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"stock": "1000:0, 500:1"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{500: 1, 250: 2}, responsePacks(t, response))
+	})
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"stock": "250:1,500:0,1000:0"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("Errorf", http.StatusUnprocessableEntity, mock.Anything, mock.Anything).
+			Return(service.ErrInsufficientStock)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.ErrorIs(t, err, service.ErrInsufficientStock)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.statusCode)
+	})
+
+	t.Run("invalid stock", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"stock": "250=1"})
+
+		response.On("BadRequest", "invalid stock: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesWithStrategy(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250, Cost: 1},
+			{ID: "item-2", PackID: "pack-1", Size: 500, Cost: 3},
+			{ID: "item-3", PackID: "pack-1", Size: 1000, Cost: 5},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		strategy  string
+		objective string
+		expected  map[int64]int64
+	}{
+		{name: "default", strategy: "", expected: map[int64]int64{1000: 1}},
+		{name: "least overshoot", strategy: "least_overshoot", expected: map[int64]int64{1000: 1}},
+		{name: "fewest packs", strategy: "fewest_packs", expected: map[int64]int64{1000: 1}},
+		{name: "cheapest", strategy: "cheapest", expected: map[int64]int64{250: 4}},
+		{name: "custom priority", strategy: "lexicographic:cost,packs", expected: map[int64]int64{250: 4}},
+		{name: "objective alias", objective: "cheapest", expected: map[int64]int64{250: 4}},
+		{name: "same objective", strategy: "cheapest", objective: "cheapest", expected: map[int64]int64{250: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewPackagingService(mockStore)
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+
+			request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+			request.On("String", "packs_hash", mock.Anything).Return("abc123")
+			mockOptionalParameters(request, map[string]string{"strategy": tt.strategy, "objective": tt.objective})
+
+			mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+			mockResponseWriter(response)
+			response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+			err := api.NumberOfPackages(context.Background(), request, response)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, responsePacks(t, response))
+		})
+	}
+
+	t.Run("no combination within packs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(3000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"strategy": "within_packs:2"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("Errorf", http.StatusUnprocessableEntity, mock.Anything, mock.Anything).
+			Return(service.ErrNoCombination)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.ErrorIs(t, err, service.ErrNoCombination)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.statusCode)
+	})
+
+	t.Run("conflicting objective", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"strategy": "fewest_packs", "objective": "cheapest"})
+
+		response.On("BadRequest", "objective %q conflicts with strategy %q", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"strategy": "fastest"})
+
+		response.On("BadRequest", "invalid strategy: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesWithTolerance(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		amount   int64
+		expected map[int64]int64
+		fit      service.Fit
+	}{
+		{name: "exact", amount: 1000, expected: map[int64]int64{1000: 1}, fit: service.FitExact},
+		{name: "over", amount: 1001, expected: map[int64]int64{250: 1, 1000: 1}, fit: service.FitOver},
+		{
+			name:     "under in percent",
+			values:   map[string]string{"tolerance_under": "2%"},
+			amount:   1001,
+			expected: map[int64]int64{1000: 1},
+			fit:      service.FitUnder,
+		},
+		{
+			name:     "over in items",
+			values:   map[string]string{"tolerance_over": "250"},
+			amount:   750,
+			expected: map[int64]int64{1000: 1},
+			fit:      service.FitOver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewPackagingService(mockStore)
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+
+			request.On("Integer", "amount", mock.Anything).Return(tt.amount)
+			request.On("String", "packs_hash", mock.Anything).Return("abc123")
+			mockOptionalParameters(request, tt.values)
+
+			mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+			recorder := mockResponseWriter(response)
+			response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+			err := api.NumberOfPackages(context.Background(), request, response)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, responsePacks(t, response))
+			assert.Equal(t, string(tt.fit), recorder.Header().Get(fitHeader))
+		})
+	}
+
+	t.Run("invalid tolerance", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"tolerance_over": "-5%"})
+
+		response.On("BadRequest", "invalid tolerance_over: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesWithShipmentLimits(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250, Weight: 3, Volume: 2},
+			{ID: "item-2", PackID: "pack-1", Size: 500, Weight: 5, Volume: 4},
+			{ID: "item-3", PackID: "pack-1", Size: 1000, Weight: 9, Volume: 8},
+		},
+	}
+
+	t.Run("split into shipments", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(3250))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_weight": "20"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.CalculationResponse)
+		require.True(t, ok)
+		assert.Equal(t, []model.Shipment{
+			{Lines: []model.PackLine{{Size: 1000, Count: 2}}, Weight: 18, Volume: 16, Count: 1},
+			{Lines: []model.PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 1}}, Weight: 12, Volume: 10, Count: 1},
+		}, data.Shipments)
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(3250))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, nil)
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.CalculationResponse)
+		require.True(t, ok)
+		assert.Nil(t, data.Shipments)
+	})
+
+	t.Run("pack over limits", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_volume": "7"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("Errorf", http.StatusUnprocessableEntity, mock.Anything, mock.Anything).
+			Return(service.ErrPackOverLimits)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.ErrorIs(t, err, service.ErrPackOverLimits)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.statusCode)
+	})
+
+	t.Run("combined with legacy", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_weight": "20", "legacy": "true"})
+
+		response.On("BadRequest", "shipment limits can't be combined with legacy or explain", mock.Anything).
+			Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesInParcels(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	t.Run("balanced parcels", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(12001))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs_per_parcel": "5"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.CalculationResponse)
+		require.True(t, ok)
+		assert.Equal(t, []model.Parcel{
+			{Lines: []model.PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 4}}, Packs: 5, First: 1, Count: 1},
+			{Lines: []model.PackLine{{Size: 1000, Count: 4}}, Packs: 4, First: 2, Count: 2},
+		}, data.Parcels)
+	})
+
+	t.Run("cost strategy with loose stored rules", func(t *testing.T) {
+		limited := model.Pack{
+			ID:          "pack-1",
+			VersionHash: "abc123",
+			PackItems: []model.PackItem{
+				{ID: "item-1", PackID: "pack-1", Size: 250},
+				{ID: "item-2", PackID: "pack-1", Size: 500},
+				{ID: "item-3", PackID: "pack-1", Size: 1000, MaxCount: 40},
+			},
+		}
+
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs": "3", "strategy": "cheapest"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&limited, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 1, 1000: 1}, responsePacks(t, response))
+	})
+
+	t.Run("cost strategy with stock", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs": "3", "strategy": "cheapest", "stock": "1000:1"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("BadRequest", "can't calculate number of packages: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs_per_parcel": "0"})
+
+		response.On("BadRequest", "invalid max_packs_per_parcel: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+
+	t.Run("combined with shipment limits", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs_per_parcel": "5", "max_weight": "20"})
+
+		response.On("BadRequest", "max_packs_per_parcel can't be combined with legacy, explain or shipment limits", mock.Anything).
+			Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesWithMaxPacks(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(501))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs": "1"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.CalculationResponse)
+		require.True(t, ok)
+		assert.Equal(t, []model.PackLine{{Size: 1000, Count: 1}}, data.Lines)
+	})
+
+	t.Run("infeasible", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs": "1"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("Object", http.StatusUnprocessableEntity, mock.AnythingOfType("model.InfeasibleResponse")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.statusCode)
+
+		data, ok := response.data.(model.InfeasibleResponse)
+		require.True(t, ok)
+		assert.Equal(t, int64(1), data.MaxPacks)
+		assert.Equal(t, int64(2), data.MinPacks)
+		assert.NotEmpty(t, data.Error)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"max_packs": "-2"})
+
+		response.On("BadRequest", "invalid max_packs: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_NumberOfPackagesWithRules(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 1000, Step: 4},
+		},
+	}
+
+	mockStore := mock_store.NewMockStore(gomock.NewController(t))
+	api := NewPackagingService(mockStore)
+
+	request := &MockRequest{}
+	response := &MockResponse{}
+
+	request.On("Integer", "amount", mock.Anything).Return(int64(3900))
+	request.On("String", "packs_hash", mock.Anything).Return("abc123")
+	mockOptionalParameters(request, nil)
+
+	mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+	mockResponseWriter(response)
+	response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+	err := api.NumberOfPackages(context.Background(), request, response)
+
+	require.NoError(t, err)
+
+	data, ok := response.data.(model.CalculationResponse)
+	require.True(t, ok)
+	assert.Equal(t, []model.PackLine{{Size: 1000, Count: 4}}, data.Lines)
+}
+
+func TestPackagingService_NumberOfPackagesWithHugeRules(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 5, MaxCount: 9e18},
+			{ID: "item-2", PackID: "pack-1", Size: 7, MinCount: 9e18},
+		},
+	}
+
+	mockStore := mock_store.NewMockStore(gomock.NewController(t))
+	api := NewPackagingService(mockStore)
+
+	request := &MockRequest{}
+	response := &MockResponse{}
+
+	request.On("Integer", "amount", mock.Anything).Return(int64(100))
+	request.On("String", "packs_hash", mock.Anything).Return("abc123")
+	mockOptionalParameters(request, nil)
+
+	mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+	mockResponseWriter(response)
+	response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
+
+	err := api.NumberOfPackages(context.Background(), request, response)
+
+	require.NoError(t, err)
+
+	data, ok := response.data.(model.CalculationResponse)
+	require.True(t, ok)
+	assert.Equal(t, []model.PackLine{{Size: 5, Count: 20}}, data.Lines)
+}
+
+func TestPackagingService_NumberOfPackagesStopped(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+		},
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		status int
+	}{
+		{name: "client canceled", ctx: canceled, status: http.StatusRequestTimeout},
+		{name: "budget exceeded", ctx: expired, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewPackagingService(mockStore)
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+			expectedError := errors.New("stopped")
+
+			request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+			request.On("String", "packs_hash", mock.Anything).Return("abc123")
+			mockOptionalParameters(request, nil)
+
+			mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+			response.On("Errorf", tt.status, mock.Anything, mock.Anything).Return(expectedError)
+
+			err := api.NumberOfPackages(tt.ctx, request, response)
+
+			assert.Equal(t, expectedError, err)
+			assert.Equal(t, tt.status, response.statusCode)
+		})
+	}
+}
+
+func TestPackagingService_NumberOfPackagesInputLimits(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		limits service.InputLimits
+		status int
+	}{
+		{name: "amount too large", limits: service.InputLimits{MaxAmount: 999}, status: http.StatusBadRequest},
+		{name: "too many pack sizes", limits: service.InputLimits{MaxPackSizes: 2}, status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mock_store.NewMockStore(gomock.NewController(t))
+			api := NewPackagingService(mockStore, service.WithInputLimits(tt.limits))
+
+			request := &MockRequest{}
+			response := &MockResponse{}
+			expectedError := errors.New("rejected")
+
+			request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+			request.On("String", "packs_hash", mock.Anything).Return("abc123")
+			mockOptionalParameters(request, nil)
+
+			mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+			if tt.status == http.StatusBadRequest {
+				response.On("BadRequest", mock.Anything, mock.Anything).Return(expectedError)
+			} else {
+				response.On("Errorf", tt.status, mock.Anything, mock.Anything).Return(expectedError)
+			}
+
+			err := api.NumberOfPackages(context.Background(), request, response)
+
+			assert.Equal(t, expectedError, err)
+			assert.Equal(t, tt.status, response.statusCode)
+		})
+	}
+}
+
+func TestPackagingService_NumberOfPackagesParetoFront(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 1, Cost: 1},
+			{ID: "item-2", PackID: "pack-1", Size: 10, Cost: 4},
+		},
+	}
+
+	t.Run("trade-offs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(25))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"pareto": "true"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("OK", mock.AnythingOfType("model.CalculationParetoFront")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.CalculationParetoFront{
+			RequestedAmount: 25,
+			VersionHash:     "abc123",
+			Front: []model.CalculationCandidate{
+				{Packs: map[int64]int64{1: 5, 10: 2}, Shipped: 25, NumberOfPacks: 7, Cost: 13},
+				{Packs: map[int64]int64{10: 3}, Shipped: 30, Overshoot: 5, NumberOfPacks: 3, Cost: 12},
+			},
+		}, response.data)
+	})
+
+	t.Run("combined with explain", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(25))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"pareto": "true", "explain": "true"})
+
+		response.On("BadRequest", "pareto can't be combined with legacy, explain, shipment limits or parcels", mock.Anything).
+			Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		weight      string
+		volume      string
+		expected    service.Limits
+		expectError bool
+	}{
+		{name: "empty", expected: service.Limits{}},
+		{name: "weight", weight: "20", expected: service.Limits{Weight: 20}},
+		{name: "both", weight: "20", volume: "8", expected: service.Limits{Weight: 20, Volume: 8}},
+		{name: "zero", volume: "0", expectError: true},
+		{name: "negative", weight: "-1", expectError: true},
+		{name: "not a number", weight: "abc", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := parseLimits(tt.weight, tt.volume)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, limits)
+		})
+	}
+}
+
+func TestPackagingService_NumberOfPackagesExplained(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250, Cost: 1},
+			{ID: "item-2", PackID: "pack-1", Size: 500, Cost: 3},
+			{ID: "item-3", PackID: "pack-1", Size: 1000, Cost: 5},
+		},
+	}
+
+	t.Run("successful explanation", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"explain": "true", "alternatives": "1"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		recorder := mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationExplanation")).Return(nil)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.CalculationExplanation{
+			Strategy: "least_overshoot",
+			Chosen: model.CalculationCandidate{
+				Packs:         map[int64]int64{250: 1, 1000: 1},
+				Shipped:       1250,
+				Overshoot:     249,
+				NumberOfPacks: 2,
+				Cost:          6,
+			},
+			Alternatives: []model.CalculationCandidate{
+				{
+					Packs:         map[int64]int64{500: 1, 1000: 1},
+					Shipped:       1500,
+					Overshoot:     499,
+					NumberOfPacks: 2,
+					Cost:          8,
+				},
+			},
+		}, response.data)
+		assert.Equal(t, string(service.FitOver), recorder.Header().Get(fitHeader))
+	})
+
+	t.Run("no combination", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(3000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"explain": "true", "strategy": "within_packs:2"})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		response.On("Errorf", http.StatusUnprocessableEntity, mock.Anything, mock.Anything).
+			Return(service.ErrNoCombination)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.ErrorIs(t, err, service.ErrNoCombination)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.statusCode)
+	})
+
+	t.Run("invalid alternatives", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Integer", "amount", mock.Anything).Return(int64(1000))
+		request.On("String", "packs_hash", mock.Anything).Return("abc123")
+		mockOptionalParameters(request, map[string]string{"explain": "true", "alternatives": "100"})
+
+		response.On("BadRequest", "invalid explanation: %s", mock.Anything).Return(expectedError)
+
+		err := api.NumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestParseExplain(t *testing.T) {
+	tests := []struct {
+		name           string
+		explain        string
+		alternatives   string
+		expected       bool
+		expectedNumber int
+		expectError    bool
+	}{
+		{name: "not set", expected: false, expectedNumber: defaultAlternatives},
+		{name: "enabled", explain: "true", expected: true, expectedNumber: defaultAlternatives},
+		{name: "alternatives", explain: "1", alternatives: "5", expected: true, expectedNumber: 5},
+		{name: "no alternatives", explain: "true", alternatives: "0", expected: true, expectedNumber: 0},
+		{name: "invalid flag", explain: "sure", expectError: true},
+		{name: "negative alternatives", explain: "true", alternatives: "-1", expectError: true},
+		{name: "too many alternatives", explain: "true", alternatives: "11", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explain, alternatives, err := parseExplain(tt.explain, tt.alternatives)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, explain)
+			assert.Equal(t, tt.expectedNumber, alternatives)
+		})
+	}
+}
+
+func TestPackagingService_BatchNumberOfPackages(t *testing.T) {
+	pack := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "abc123",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+
+	t.Run("successful calculation", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.BatchCalculationRequest{
+			Items: []model.BatchCalculationItem{
+				{Amount: 1001, PacksHash: "abc123"},
+				{Amount: 500, PacksHash: "missing"},
+				{Amount: 0, PacksHash: "abc123"},
+				{Amount: 250, PacksHash: "abc123"},
+				{Amount: 1000, PacksHash: ""},
+			},
+		})
+
+		// Every configuration is fetched once
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(&pack, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "missing").Return(nil, store.ErrNotFound)
+
+		response.On("OK", mock.AnythingOfType("model.BatchCalculationResponse")).Return(nil)
+
+		err := api.BatchNumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.BatchCalculationResponse{
+			Results: []model.BatchCalculationResult{
+				{Amount: 1001, PacksHash: "abc123", Packs: map[int64]int64{250: 1, 1000: 1}},
+				{Amount: 500, PacksHash: "missing", Error: "packs not found by hash: missing"},
+				{Amount: 0, PacksHash: "abc123", Error: "amount must be greater than 0"},
+				{Amount: 250, PacksHash: "abc123", Packs: map[int64]int64{250: 1}},
+				{Amount: 1000, PacksHash: "", Error: "packs_hash can't be empty"},
+			},
+		}, response.data)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.BatchCalculationRequest{
+			Items: []model.BatchCalculationItem{{Amount: 1000, PacksHash: "abc123"}},
+		})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "abc123").Return(nil, errors.New("connection lost"))
+		response.On("OK", mock.AnythingOfType("model.BatchCalculationResponse")).Return(nil)
+
+		err := api.BatchNumberOfPackages(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		results := response.data.(model.BatchCalculationResponse).Results
+		require.Len(t, results, 1)
+		assert.Equal(t, "failed to get packs: connection lost", results[0].Error)
+	})
+
+	t.Run("empty items", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Body").Return(&model.BatchCalculationRequest{})
+		response.On("BadRequest", "items can't be empty", mock.Anything).Return(expectedError)
+
+		err := api.BatchNumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+		expectedError := errors.New("bad request")
+
+		request.On("Body").Return(&model.BatchCalculationRequest{
+			Items: make([]model.BatchCalculationItem, maxBatchItems+1),
+		})
+		response.On("BadRequest", "too many items: %d (max %d)", mock.Anything).Return(expectedError)
+
+		err := api.BatchNumberOfPackages(context.Background(), request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, http.StatusBadRequest, response.statusCode)
+	})
+}
+
+func TestPackagingService_PackOrder(t *testing.T) {
+	bolts := model.Pack{
+		ID:          "pack-1",
+		VersionHash: "bolts",
+		PackItems: []model.PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+			{ID: "item-3", PackID: "pack-1", Size: 1000},
+		},
+	}
+	nuts := model.Pack{
+		ID:          "pack-2",
+		VersionHash: "nuts",
+		PackItems: []model.PackItem{
+			{ID: "item-4", PackID: "pack-2", Size: 100},
+			{ID: "item-5", PackID: "pack-2", Size: 250},
+		},
+	}
+
+	t.Run("successful calculation", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.OrderRequest{
+			Lines: []model.OrderLine{
+				{SKU: "bolt-m8", Amount: 1001, PacksHash: "bolts"},
+				{SKU: "nut-m8", Amount: 350, PacksHash: "nuts"},
+				{SKU: "bolt-m10", Amount: 500, PacksHash: "bolts"},
+			},
+		})
+
+		// Every configuration is fetched once
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "bolts").Return(&bolts, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "nuts").Return(&nuts, nil)
+
+		response.On("OK", mock.AnythingOfType("model.OrderResponse")).Return(nil)
+
+		err := api.PackOrder(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.OrderResponse)
+		require.True(t, ok)
+		require.Len(t, data.Lines, 3)
+
+		assert.Equal(t, "bolt-m8", data.Lines[0].SKU)
+		assert.Equal(t, []model.PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 1}}, data.Lines[0].Lines)
+		assert.Equal(t, "nut-m8", data.Lines[1].SKU)
+		assert.Equal(t, []model.PackLine{{Size: 100, Count: 1}, {Size: 250, Count: 1}}, data.Lines[1].Lines)
+		assert.Equal(t, "nuts", data.Lines[1].VersionHash)
+		assert.Equal(t, "bolt-m10", data.Lines[2].SKU)
+		assert.Equal(t, []model.PackLine{{Size: 500, Count: 1}}, data.Lines[2].Lines)
+
+		assert.Equal(t, []model.PackLine{
+			{Size: 100, Count: 1},
+			{Size: 250, Count: 2},
+			{Size: 500, Count: 1},
+			{Size: 1000, Count: 1},
+		}, data.Totals)
+		assert.Equal(t, int64(5), data.TotalPacks)
+	})
+
+	t.Run("packs not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPackagingService(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.OrderRequest{
+			Lines: []model.OrderLine{
+				{SKU: "bolt-m8", Amount: 1001, PacksHash: "bolts"},
+				{SKU: "washer", Amount: 10, PacksHash: "missing"},
+			},
+		})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "bolts").Return(&bolts, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "missing").Return(nil, store.ErrNotFound)
+
+		response.On("NotFound", "line %d (%s): packs not found by hash: %s", mock.Anything).Return(store.ErrNotFound)
+
+		err := api.PackOrder(context.Background(), request, response)
+
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		assert.Equal(t, http.StatusNotFound, response.statusCode)
+	})
+
+	t.Run("invalid lines", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			body   *model.OrderRequest
+			format string
+		}{
+			{name: "empty", body: &model.OrderRequest{}, format: "lines can't be empty"},
+			{
+				name:   "too many lines",
+				body:   &model.OrderRequest{Lines: make([]model.OrderLine, maxOrderLines+1)},
+				format: "too many lines: %d (max %d)",
+			},
+			{
+				name:   "zero amount",
+				body:   &model.OrderRequest{Lines: []model.OrderLine{{SKU: "bolt", PacksHash: "bolts"}}},
+				format: "line %d (%s): amount must be greater than 0",
+			},
+			{
+				name:   "empty hash",
+				body:   &model.OrderRequest{Lines: []model.OrderLine{{SKU: "bolt", Amount: 5}}},
+				format: "line %d (%s): packs_hash can't be empty",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockStore := mock_store.NewMockStore(gomock.NewController(t))
+				api := NewPackagingService(mockStore)
+
+				request := &MockRequest{}
+				response := &MockResponse{}
+				expectedError := errors.New("bad request")
+
+				request.On("Body").Return(tt.body)
+				response.On("BadRequest", tt.format, mock.Anything).Return(expectedError)
+
+				err := api.PackOrder(context.Background(), request, response)
+
+				assert.Equal(t, expectedError, err)
+				assert.Equal(t, http.StatusBadRequest, response.statusCode)
+			})
+		}
+	})
+}
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    service.Tolerance
+		expectError bool
+	}{
+		{name: "empty", raw: "", expected: service.Tolerance{}},
+		{name: "items", raw: "20", expected: service.Tolerance{Items: 20}},
+		{name: "percent", raw: "2.5%", expected: service.Tolerance{Percent: 2.5}},
+		{name: "negative items", raw: "-1", expectError: true},
+		{name: "negative percent", raw: "-1%", expectError: true},
+		{name: "not a number", raw: "abc", expectError: true},
+		{name: "not a number percent", raw: "NaN%", expectError: true},
+		{name: "fractional items", raw: "1.5", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tolerance, err := parseTolerance(tt.raw)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tolerance)
+		})
+	}
+}
+
+func TestParseStock(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    map[int64]int64
+		expectError bool
+	}{
+		{name: "empty", raw: "", expected: nil},
+		{name: "single size", raw: "5000:3", expected: map[int64]int64{5000: 3}},
+		{name: "several sizes", raw: "5000:0, 250:12", expected: map[int64]int64{5000: 0, 250: 12}},
+		{name: "missing count", raw: "5000", expectError: true},
+		{name: "negative count", raw: "5000:-1", expectError: true},
+		{name: "zero size", raw: "0:1", expectError: true},
+		{name: "not a number", raw: "abc:1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock, err := parseStock(tt.raw)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stock)
+		})
+	}
+}
+
+// mockOptionalParameters mocks optional query parameters of calculation requests.
+// Parameters absent from values are treated as not provided.
+func mockOptionalParameters(request *MockRequest, values map[string]string) {
+	var keys = []string{"stock", "strategy", "objective", "tolerance_under", "tolerance_over", "explain", "alternatives", "legacy",
+		"max_packs", "max_weight", "max_volume", "max_packs_per_parcel", "pareto"}
+	for _, key := range keys {
+		request.On("String", key, mock.Anything).Return(values[key]).Maybe()
+	}
+}
+
+// responsePacks returns pack counts by size from the calculation response.
+func responsePacks(t *testing.T, response *MockResponse) map[int64]int64 {
+	t.Helper()
+
+	data, ok := response.data.(model.CalculationResponse)
+	require.True(t, ok)
+
+	var packs = make(map[int64]int64, len(data.Lines))
+	for _, line := range data.Lines {
+		packs[line.Size] = line.Count
+	}
+
+	return packs
+}
+
+// mockResponseWriter mocks the writer receiving headers of the response and returns it.
+func mockResponseWriter(response *MockResponse) *httptest.ResponseRecorder {
+	var recorder = httptest.NewRecorder()
+
+	response.On("ResponseWriter").Return(recorder)
+
+	return recorder
+}
+
 func TestPackagingService_Routes(t *testing.T) {
 	mockStore := mock_store.NewMockStore(gomock.NewController(t))
 	api := NewPackagingService(mockStore)
@@ -271,10 +1709,12 @@ func TestPackagingService_ContextHandling(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil)
 
-		response.On("OK", mock.AnythingOfType("map[int64]int64")).Return(nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
 
 		err := api.NumberOfPackages(ctx, request, response)
 
@@ -299,6 +1739,7 @@ func TestPackagingService_ContextHandling(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		// Mock store to return context canceled error
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(nil, context.Canceled)
@@ -328,6 +1769,7 @@ func TestPackagingService_EdgeCases(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(nil, store.ErrNotFound)
 		response.On("NotFound", "packs not found by hash: %s", mock.Anything).Return(store.ErrNotFound)
@@ -365,9 +1807,11 @@ func TestPackagingService_EdgeCases(t *testing.T) {
 
 		request.On("Integer", "amount", mock.Anything).Return(amount)
 		request.On("String", "packs_hash", mock.Anything).Return(versionHash)
+		mockOptionalParameters(request, nil)
 
 		mockStore.EXPECT().GetPackByHash(gomock.Any(), versionHash).Return(&pack, nil)
-		response.On("OK", mock.AnythingOfType("map[int64]int64")).Return(nil)
+		mockResponseWriter(response)
+		response.On("OK", mock.AnythingOfType("model.CalculationResponse")).Return(nil)
 
 		err := api.NumberOfPackages(ctx, request, response)
 
@@ -375,8 +1819,7 @@ func TestPackagingService_EdgeCases(t *testing.T) {
 		assert.Equal(t, 200, response.statusCode)
 
 		// Negative amount should result in empty map
-		responseData, ok := response.data.(map[int64]int64)
-		require.True(t, ok)
+		responseData := responsePacks(t, response)
 		assert.Empty(t, responseData)
 
 		request.AssertExpectations(t)
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/kliuchnikovv/engi"
+	"github.com/kliuchnikovv/engi/definition/middlewares/auth"
+	"github.com/kliuchnikovv/engi/definition/middlewares/cors"
+	"github.com/kliuchnikovv/engi/definition/parameter"
+	"github.com/kliuchnikovv/engi/definition/parameter/placing"
+	"github.com/kliuchnikovv/engi/definition/parameter/query"
+	"github.com/kliuchnikovv/engi/definition/validate"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/service"
+	"github.com/kliuchnikovv/packulator/internal/store"
+)
+
+// HierarchiesAPI provides endpoints for multi-level packaging hierarchies,
+// e.g. packs inside cartons onto pallets.
+type HierarchiesAPI struct {
+	hierarchyService service.HierarchyService // Service layer for hierarchy operations
+	options          []service.Option         // Options applied to every calculation
+}
+
+// NewHierarchiesAPI creates a new hierarchies API instance with the given store.
+// Provided options, such as a table cache, are applied to every calculation.
+func NewHierarchiesAPI(store store.Store, opts ...service.Option) *HierarchiesAPI {
+	return &HierarchiesAPI{
+		hierarchyService: service.NewHierarchyService(store),
+		options:          opts,
+	}
+}
+
+// Prefix returns the URL prefix for all hierarchy endpoints.
+func (c *HierarchiesAPI) Prefix() string {
+	return "hierarchies"
+}
+
+// Middlewares returns the middleware stack for hierarchy endpoints.
+// Allows all origins, headers, methods and requires no authentication.
+func (c *HierarchiesAPI) Middlewares() []engi.Middleware {
+	return []engi.Middleware{
+		cors.AllowedOrigins("*"),
+		cors.AllowedHeaders("*"),
+		cors.AllowedMethods("*"),
+		auth.NoAuth(),
+	}
+}
+
+// Routers defines the available hierarchy routes:
+// POST /hierarchies/create - Create new packaging hierarchy
+// GET /hierarchies/id - Get specific hierarchy by ID
+// GET /hierarchies/plan - Calculate nested packing plan of an amount
+//
+// Optional query parameters of the plan:
+//   - strategy - what to optimize on every level, see GET /packaging/number_of_packages
+//   - tolerance_under - allowed shortfall of items in items or percent of the amount
+//   - tolerance_over - allowed excess of items not counted as overshoot
+func (c *HierarchiesAPI) Routers() engi.Routes {
+	return engi.Routes{
+		engi.PST("create"): engi.Handle(
+			c.CreateHierarchy,
+			parameter.Body(new(model.CreateHierarchyRequest)),
+		),
+		engi.GET("id"): engi.Handle(
+			c.GetHierarchyByID,
+			query.String("id", validate.NotEmpty),
+		),
+		engi.GET("plan"): engi.Handle(
+			c.PlanHierarchy,
+			query.String("id", validate.NotEmpty),        // Required: hierarchy ID
+			query.Integer("amount", validate.Greater(0)), // Required: amount > 0
+		),
+	}
+}
+
+// CreateHierarchy handles POST /hierarchies/create requests.
+// It creates a packaging hierarchy from levels ordered from the innermost one,
+// every level referencing the pack configuration of its container capacities.
+func (c *HierarchiesAPI) CreateHierarchy(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	body, ok := request.Body().(*model.CreateHierarchyRequest)
+	if !ok || len(body.Levels) == 0 {
+		return response.BadRequest("levels can't be empty")
+	}
+
+	if body.Name == "" {
+		return response.BadRequest("name can't be empty")
+	}
+
+	var levels = make([]model.HierarchyLevel, len(body.Levels))
+	for i, level := range body.Levels {
+		levels[i] = model.HierarchyLevel{Name: level.Name, PacksHash: level.PacksHash}
+	}
+
+	id, err := c.hierarchyService.CreateHierarchy(ctx, body.Name, levels...)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidHierarchy):
+		return response.BadRequest("%s", err)
+	default:
+		return response.InternalServerError("can't create hierarchy: %s", err)
+	}
+
+	return response.OK(model.CreateHierarchyResponse{
+		ID: id,
+	})
+}
+
+// GetHierarchyByID handles GET /hierarchies/id requests.
+// It retrieves a specific packaging hierarchy with its levels by its unique ID.
+func (c *HierarchiesAPI) GetHierarchyByID(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	var id = request.String("id", placing.InQuery)
+
+	hierarchy, err := c.hierarchyService.GetHierarchyByID(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrNotFound):
+		return response.NotFound("hierarchy not found by id: %s", id)
+	default:
+		return response.InternalServerError("can't get hierarchy by id - %s: %s", id, err)
+	}
+
+	return response.OK(hierarchy)
+}
+
+// PlanHierarchy handles GET /hierarchies/plan requests.
+// It calculates packing of the amount on every level of the hierarchy and responds
+// with the nested plan from the outermost level.
+func (c *HierarchiesAPI) PlanHierarchy(
+	ctx context.Context,
+	request engi.Request,
+	response engi.Response,
+) error {
+	var (
+		id      = request.String("id", placing.InQuery)
+		amount  = request.Integer("amount", placing.InQuery)
+		options = slices.Clone(c.options)
+	)
+
+	// Parse optional calculation strategy
+	strategy, err := service.ParseStrategy(request.String("strategy", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid strategy: %s", err)
+	}
+
+	// Parse optional tolerances
+	under, err := parseTolerance(request.String("tolerance_under", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid tolerance_under: %s", err)
+	}
+
+	over, err := parseTolerance(request.String("tolerance_over", placing.InQuery))
+	if err != nil {
+		return response.BadRequest("invalid tolerance_over: %s", err)
+	}
+
+	options = append(options,
+		service.WithStrategy(strategy),
+		service.WithTolerance(under, over),
+	)
+
+	plans, err := c.hierarchyService.PlanHierarchy(ctx, id, amount, options...)
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrNotFound):
+		return response.NotFound("hierarchy or its packs not found: %s", err)
+	default:
+		return calculationError(response, err)
+	}
+
+	return response.OK(model.HierarchyPlanResponse{
+		HierarchyID:     id,
+		RequestedAmount: amount,
+		Plan:            newPackingPlan(plans),
+	})
+}
+
+// newPackingPlan nests plans of hierarchy levels ordered from the innermost one
+// into the plan of the outermost level.
+func newPackingPlan(plans []service.LevelPlan) *model.PackingPlan {
+	var plan *model.PackingPlan
+
+	for _, level := range plans {
+		plan = &model.PackingPlan{
+			Level:      level.Name,
+			Units:      level.Units,
+			Containers: model.NewPackLines(level.Containers),
+			Count:      level.Count,
+			Contents:   plan,
+		}
+	}
+
+	return plan
+}
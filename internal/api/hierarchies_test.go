@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/service"
+	"github.com/kliuchnikovv/packulator/internal/store"
+	mock_store "github.com/kliuchnikovv/packulator/internal/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewHierarchiesAPI(t *testing.T) {
+	mockStore := mock_store.NewMockStore(gomock.NewController(t))
+	api := NewHierarchiesAPI(mockStore)
+
+	assert.NotNil(t, api)
+	assert.NotNil(t, api.hierarchyService)
+	assert.Equal(t, "hierarchies", api.Prefix())
+	assert.Len(t, api.Routers(), 3)
+}
+
+func TestHierarchiesAPI_CreateHierarchy(t *testing.T) {
+	t.Run("successful creation", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.CreateHierarchyRequest{
+			Name: "pallets",
+			Levels: []model.CreateHierarchyLevel{
+				{Name: "pack", PacksHash: "packs"},
+				{Name: "carton", PacksHash: "cartons"},
+			},
+		})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(&model.Pack{}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{}, nil)
+		mockStore.EXPECT().SaveHierarchy(gomock.Any(), gomock.Any()).Return(nil)
+
+		response.On("OK", mock.AnythingOfType("model.CreateHierarchyResponse")).Return(nil)
+
+		err := api.CreateHierarchy(context.Background(), request, response)
+
+		require.NoError(t, err)
+
+		data, ok := response.data.(model.CreateHierarchyResponse)
+		require.True(t, ok)
+		assert.NotEmpty(t, data.ID)
+	})
+
+	t.Run("unknown packs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.CreateHierarchyRequest{
+			Name:   "pallets",
+			Levels: []model.CreateHierarchyLevel{{Name: "pack", PacksHash: "nonexistent"}},
+		})
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "nonexistent").Return(nil, store.ErrNotFound)
+
+		response.On("BadRequest", "%s", mock.Anything).Return(errors.New("invalid hierarchy"))
+
+		err := api.CreateHierarchy(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 400, response.statusCode)
+	})
+
+	t.Run("empty levels", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.CreateHierarchyRequest{Name: "pallets"})
+		response.On("BadRequest", "levels can't be empty", mock.Anything).Return(errors.New("levels can't be empty"))
+
+		err := api.CreateHierarchy(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 400, response.statusCode)
+	})
+}
+
+func TestHierarchiesAPI_GetHierarchyByID(t *testing.T) {
+	t.Run("successful retrieval", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expected := &model.Hierarchy{ID: "hierarchy-1", Name: "pallets"}
+
+		request.On("String", "id", mock.Anything).Return("hierarchy-1")
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(expected, nil)
+		response.On("OK", expected).Return(nil)
+
+		err := api.GetHierarchyByID(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, response.data)
+	})
+
+	t.Run("hierarchy not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "id", mock.Anything).Return("nonexistent")
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "nonexistent").Return(nil, store.ErrNotFound)
+		response.On("NotFound", "hierarchy not found by id: %s", mock.Anything).Return(store.ErrNotFound)
+
+		err := api.GetHierarchyByID(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 404, response.statusCode)
+	})
+}
+
+func TestHierarchiesAPI_PlanHierarchy(t *testing.T) {
+	hierarchy := &model.Hierarchy{
+		ID: "hierarchy-1",
+		Levels: []model.HierarchyLevel{
+			{Name: "pack", PacksHash: "packs"},
+			{Name: "carton", PacksHash: "cartons"},
+		},
+	}
+
+	t.Run("successful planning", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "id", mock.Anything).Return("hierarchy-1")
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		mockOptionalParameters(request, nil)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(hierarchy, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 250}, {Size: 500}, {Size: 1000}},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 4}},
+		}, nil)
+
+		response.On("OK", mock.AnythingOfType("model.HierarchyPlanResponse")).Return(nil)
+
+		err := api.PlanHierarchy(context.Background(), request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.HierarchyPlanResponse{
+			HierarchyID:     "hierarchy-1",
+			RequestedAmount: 1001,
+			Plan: &model.PackingPlan{
+				Level:      "carton",
+				Units:      2,
+				Containers: []model.PackLine{{Size: 4, Count: 1}},
+				Count:      1,
+				Contents: &model.PackingPlan{
+					Level:      "pack",
+					Units:      1001,
+					Containers: []model.PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 1}},
+					Count:      2,
+				},
+			},
+		}, response.data)
+	})
+
+	t.Run("hierarchy not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "id", mock.Anything).Return("nonexistent")
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		mockOptionalParameters(request, nil)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "nonexistent").Return(nil, store.ErrNotFound)
+		response.On("NotFound", "hierarchy or its packs not found: %s", mock.Anything).Return(store.ErrNotFound)
+
+		err := api.PlanHierarchy(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 404, response.statusCode)
+	})
+
+	t.Run("no combination", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "id", mock.Anything).Return("hierarchy-1")
+		request.On("Integer", "amount", mock.Anything).Return(int64(5000))
+		mockOptionalParameters(request, map[string]string{"strategy": "within_packs:1"})
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(hierarchy, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 1000}},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 4}},
+		}, nil)
+
+		response.On("Errorf", 422, mock.Anything, mock.Anything).Return(service.ErrNoCombination)
+
+		err := api.PlanHierarchy(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 422, response.statusCode)
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewHierarchiesAPI(mockStore)
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("String", "id", mock.Anything).Return("hierarchy-1")
+		request.On("Integer", "amount", mock.Anything).Return(int64(1001))
+		mockOptionalParameters(request, map[string]string{"strategy": "unknown"})
+
+		response.On("BadRequest", "invalid strategy: %s", mock.Anything).Return(service.ErrUnknownStrategy)
+
+		err := api.PlanHierarchy(context.Background(), request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 400, response.statusCode)
+	})
+}
@@ -2,6 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/kliuchnikovv/engi"
 	"github.com/kliuchnikovv/engi/definition/middlewares/auth"
@@ -45,7 +50,7 @@ func (c *PacksAPI) Middlewares() []engi.Middleware {
 
 // Routers defines the available pack management routes:
 // POST /packs/create - Create new pack configuration
-// GET /packs/list - List all available packs
+// GET /packs/list - List available packs page by page, filtered and sorted
 // GET /packs/id - Get specific pack by ID
 // GET /packs/hash - Get packs by version hash
 // DELETE /packs/delete - Delete pack configuration
@@ -72,7 +77,8 @@ func (c *PacksAPI) Routers() engi.Routes {
 }
 
 // CreatePacks handles POST /packs/create requests.
-// It creates a new pack configuration with the provided pack sizes.
+// It creates a new pack configuration with the provided pack sizes and optional costs,
+// weights, volumes and quantity rules, or returns the existing one with the same version hash.
 func (c *PacksAPI) CreatePacks(
 	ctx context.Context,
 	request engi.Request,
@@ -83,28 +89,99 @@ func (c *PacksAPI) CreatePacks(
 		return response.BadRequest("packs can't be empty")
 	}
 
-	versionHash, err := c.packService.CreatePacks(ctx, body.Packs...)
-	if err != nil {
+	// Attach unit costs, measures and rules to pack items
+	var items = make([]model.PackItem, len(body.Packs))
+	for i, size := range body.Packs {
+		items[i] = model.PackItem{
+			Size:     size,
+			Cost:     body.Costs[size],
+			Weight:   body.Weights[size],
+			Volume:   body.Volumes[size],
+			MinCount: body.Rules[size].MinCount,
+			MaxCount: body.Rules[size].MaxCount,
+			Step:     body.Rules[size].Step,
+		}
+	}
+
+	for size, cost := range body.Costs {
+		if cost < 0 {
+			return response.BadRequest("cost of pack %d can't be negative", size)
+		}
+
+		if !slices.Contains(body.Packs, size) {
+			return response.BadRequest("cost of unknown pack %d", size)
+		}
+	}
+
+	for _, measure := range []struct {
+		name   string
+		values map[int64]int64
+	}{
+		{name: "weight", values: body.Weights},
+		{name: "volume", values: body.Volumes},
+	} {
+		for size, value := range measure.values {
+			if value < 0 {
+				return response.BadRequest("%s of pack %d can't be negative", measure.name, size)
+			}
+
+			if !slices.Contains(body.Packs, size) {
+				return response.BadRequest("%s of unknown pack %d", measure.name, size)
+			}
+		}
+	}
+
+	for size, rule := range body.Rules {
+		if err := service.NewRule(rule).Validate(); err != nil {
+			return response.BadRequest("invalid rule of pack %d: %s", size, err)
+		}
+
+		if !slices.Contains(body.Packs, size) {
+			return response.BadRequest("rule of unknown pack %d", size)
+		}
+	}
+
+	versionHash, created, err := c.packService.CreatePackItems(ctx, items...)
+	switch {
+	case err == nil:
+		// Pack configuration created or found by its version hash
+	case errors.Is(err, service.ErrInvalidPackSize):
+		return response.BadRequest("invalid packs: %s", err)
+	default:
 		return response.InternalServerError("can't create packs: %s", err)
 	}
 
 	return response.OK(model.CreatePacksResponse{
 		VersionHash: versionHash,
+		Created:     created,
 	})
 }
 
 // ListPacks handles GET /packs/list requests.
-// It returns a list of all available pack configurations.
+// It returns a page of available pack configurations passing optional filters in the requested
+// order, along with the cursor of the next page.
 func (c *PacksAPI) ListPacks(
 	ctx context.Context,
-	_ engi.Request,
+	request engi.Request,
 	response engi.Response,
 ) error {
-	packs, err := c.packService.ListPacks(ctx)
+	query, err := parsePackQuery(request)
 	if err != nil {
+		return response.BadRequest("invalid query: %s", err)
+	}
+
+	page, err := c.packService.ListPacks(ctx, query)
+	switch {
+	case errors.Is(err, store.ErrInvalidQuery):
+		return response.BadRequest("invalid query: %s", err)
+	case err != nil:
 		return response.InternalServerError("can't list packs: %s", err)
 	}
-	return response.OK(packs)
+
+	return response.OK(model.ListPacksResponse{
+		Packs:      page.Packs,
+		NextCursor: page.NextCursor,
+	})
 }
 
 // GetPackByID handles GET /packs/id requests.
@@ -156,3 +233,75 @@ func (c *PacksAPI) DeletePack(
 
 	return response.NoContent()
 }
+
+// parsePackQuery parses optional filters, order and pagination of a pack listing.
+// Filters which aren't set are not applied.
+func parsePackQuery(request engi.Request) (store.PackQuery, error) {
+	var query = store.PackQuery{
+		HashPrefix: request.String("hash_prefix", placing.InQuery),
+		Cursor:     request.String("cursor", placing.InQuery),
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{name: "created_after", value: &query.CreatedAfter},
+		{name: "created_before", value: &query.CreatedBefore},
+	} {
+		raw := request.String(bound.name, placing.InQuery)
+		if raw == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return store.PackQuery{}, fmt.Errorf("%s must be an RFC 3339 time, got %q", bound.name, raw)
+		}
+
+		*bound.value = parsed
+	}
+
+	for _, filter := range []struct {
+		name  string
+		value *int64
+	}{
+		{name: "contains_size", value: &query.ContainsSize},
+		{name: "min_total_amount", value: &query.MinTotalAmount},
+		{name: "max_total_amount", value: &query.MaxTotalAmount},
+	} {
+		parsed, err := parseLimit(request.String(filter.name, placing.InQuery))
+		if err != nil {
+			return store.PackQuery{}, fmt.Errorf("invalid %s: %w", filter.name, err)
+		}
+
+		*filter.value = parsed
+	}
+
+	switch sortBy := request.String("sort", placing.InQuery); sortBy {
+	case "", store.SortByCreatedAt, store.SortByTotalAmount:
+		query.SortBy = sortBy
+	default:
+		return store.PackQuery{}, fmt.Errorf("sort must be %s or %s, got %q",
+			store.SortByCreatedAt, store.SortByTotalAmount, sortBy)
+	}
+
+	switch order := request.String("order", placing.InQuery); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return store.PackQuery{}, fmt.Errorf("order must be asc or desc, got %q", order)
+	}
+
+	if raw := request.String("limit", placing.InQuery); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > store.MaxPageSize {
+			return store.PackQuery{}, fmt.Errorf("limit must be from 1 to %d, got %q", store.MaxPageSize, raw)
+		}
+
+		query.Limit = limit
+	}
+
+	return query, nil
+}
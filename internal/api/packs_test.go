@@ -9,6 +9,7 @@ import (
 
 	"github.com/kliuchnikovv/engi/definition/parameter/placing"
 	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/service"
 	"github.com/kliuchnikovv/packulator/internal/store"
 	mock_store "github.com/kliuchnikovv/packulator/internal/store/mocks"
 	"github.com/stretchr/testify/assert"
@@ -230,9 +231,9 @@ func TestPacksAPI_CreatePacks(t *testing.T) {
 		// Mock request body
 		request.On("Body").Return(requestBody)
 
-		// Mock store SavePackss
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(nil)
+		// Mock store SavePackOnce
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(true, nil)
 		// Mock response
 		response.On("OK", mock.AnythingOfType("model.CreatePacksResponse")).Return(nil)
 
@@ -245,6 +246,34 @@ func TestPacksAPI_CreatePacks(t *testing.T) {
 		responseData, ok := response.data.(model.CreatePacksResponse)
 		require.True(t, ok)
 		assert.NotEmpty(t, responseData.VersionHash)
+		assert.True(t, responseData.Created)
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("existing configuration", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.CreatePacksRequest{Packs: []int64{250, 500}})
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(false, nil)
+		response.On("OK", mock.AnythingOfType("model.CreatePacksResponse")).Return(nil)
+
+		err := api.CreatePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+
+		responseData, ok := response.data.(model.CreatePacksResponse)
+		require.True(t, ok)
+		assert.NotEmpty(t, responseData.VersionHash)
+		assert.False(t, responseData.Created)
 
 		request.AssertExpectations(t)
 		response.AssertExpectations(t)
@@ -287,8 +316,8 @@ func TestPacksAPI_CreatePacks(t *testing.T) {
 		}
 
 		request.On("Body").Return(requestBody)
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(errors.New("database error"))
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(false, errors.New("database error"))
 		response.On("InternalServerError", "can't create packs: %s", mock.Anything).Return(errors.New("can't create packs"))
 
 		err := api.CreatePacks(ctx, request, response)
@@ -299,6 +328,232 @@ func TestPacksAPI_CreatePacks(t *testing.T) {
 		request.AssertExpectations(t)
 		response.AssertExpectations(t)
 	})
+
+	t.Run("creation with costs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		requestBody := &model.CreatePacksRequest{
+			Packs: []int64{250, 500},
+			Costs: map[int64]int64{250: 3},
+		}
+
+		var saved model.Pack
+
+		request.On("Body").Return(requestBody)
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pack *model.Pack) (bool, error) {
+				saved = *pack
+				return true, nil
+			})
+		response.On("OK", mock.AnythingOfType("model.CreatePacksResponse")).Return(nil)
+
+		err := api.CreatePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 3, 500: 0}, saved.GetCosts())
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("creation with measures", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		requestBody := &model.CreatePacksRequest{
+			Packs:   []int64{250, 500},
+			Weights: map[int64]int64{250: 3, 500: 5},
+			Volumes: map[int64]int64{500: 4},
+		}
+
+		var saved model.Pack
+
+		request.On("Body").Return(requestBody)
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pack *model.Pack) (bool, error) {
+				saved = *pack
+				return true, nil
+			})
+		response.On("OK", mock.AnythingOfType("model.CreatePacksResponse")).Return(nil)
+
+		err := api.CreatePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]service.Measures{250: {Weight: 3}, 500: {Weight: 5, Volume: 4}}, packMeasures(&saved))
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("invalid measures", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			weights map[int64]int64
+			volumes map[int64]int64
+			format  string
+		}{
+			{name: "negative weight", weights: map[int64]int64{250: -1}, format: "%s of pack %d can't be negative"},
+			{name: "unknown pack volume", volumes: map[int64]int64{300: 1}, format: "%s of unknown pack %d"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockStore := mock_store.NewMockStore(gomock.NewController(t))
+				api := NewPacksAPI(mockStore)
+				ctx := context.Background()
+
+				request := &MockRequest{}
+				response := &MockResponse{}
+
+				request.On("Body").Return(&model.CreatePacksRequest{
+					Packs:   []int64{250, 500},
+					Weights: tt.weights,
+					Volumes: tt.volumes,
+				})
+				response.On("BadRequest", tt.format, mock.Anything).Return(errors.New("bad request"))
+
+				err := api.CreatePacks(ctx, request, response)
+
+				assert.Error(t, err)
+				assert.Equal(t, 400, response.statusCode)
+			})
+		}
+	})
+
+	t.Run("creation with rules", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		requestBody := &model.CreatePacksRequest{
+			Packs: []int64{250, 500},
+			Rules: map[int64]model.QuantityRule{500: {MinCount: 4, MaxCount: 40, Step: 4}},
+		}
+
+		var saved model.Pack
+
+		request.On("Body").Return(requestBody)
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pack *model.Pack) (bool, error) {
+				saved = *pack
+				return true, nil
+			})
+		response.On("OK", mock.AnythingOfType("model.CreatePacksResponse")).Return(nil)
+
+		err := api.CreatePacks(ctx, request, response)
+
+		require.NoError(t, err)
+		require.Len(t, saved.PackItems, 2)
+		assert.Zero(t, saved.PackItems[0].MinCount+saved.PackItems[0].MaxCount+saved.PackItems[0].Step)
+		assert.Equal(t, int64(4), saved.PackItems[1].MinCount)
+		assert.Equal(t, int64(40), saved.PackItems[1].MaxCount)
+		assert.Equal(t, int64(4), saved.PackItems[1].Step)
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			rules  map[int64]model.QuantityRule
+			format string
+		}{
+			{name: "negative step", rules: map[int64]model.QuantityRule{250: {Step: -1}}, format: "invalid rule of pack %d: %s"},
+			{name: "no count fits", rules: map[int64]model.QuantityRule{250: {MinCount: 5, MaxCount: 4}}, format: "invalid rule of pack %d: %s"},
+			{name: "unknown pack", rules: map[int64]model.QuantityRule{300: {Step: 2}}, format: "rule of unknown pack %d"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockStore := mock_store.NewMockStore(gomock.NewController(t))
+				api := NewPacksAPI(mockStore)
+				ctx := context.Background()
+
+				request := &MockRequest{}
+				response := &MockResponse{}
+
+				request.On("Body").Return(&model.CreatePacksRequest{
+					Packs: []int64{250, 500},
+					Rules: tt.rules,
+				})
+				response.On("BadRequest", tt.format, mock.Anything).Return(errors.New("bad request"))
+
+				err := api.CreatePacks(ctx, request, response)
+
+				assert.Error(t, err)
+				assert.Equal(t, 400, response.statusCode)
+			})
+		}
+	})
+
+	t.Run("invalid pack size", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		request.On("Body").Return(&model.CreatePacksRequest{Packs: []int64{250, 0}})
+		response.On("BadRequest", "invalid packs: %s", mock.Anything).Return(errors.New("bad request"))
+
+		err := api.CreatePacks(ctx, request, response)
+
+		assert.Error(t, err)
+		assert.Equal(t, 400, response.statusCode)
+
+		request.AssertExpectations(t)
+		response.AssertExpectations(t)
+	})
+
+	t.Run("invalid costs", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			costs  map[int64]int64
+			format string
+		}{
+			{name: "negative cost", costs: map[int64]int64{250: -1}, format: "cost of pack %d can't be negative"},
+			{name: "unknown pack", costs: map[int64]int64{300: 1}, format: "cost of unknown pack %d"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockStore := mock_store.NewMockStore(gomock.NewController(t))
+				api := NewPacksAPI(mockStore)
+				ctx := context.Background()
+
+				request := &MockRequest{}
+				response := &MockResponse{}
+
+				request.On("Body").Return(&model.CreatePacksRequest{
+					Packs: []int64{250, 500},
+					Costs: tt.costs,
+				})
+				response.On("BadRequest", tt.format, mock.Anything).Return(errors.New("bad request"))
+
+				err := api.CreatePacks(ctx, request, response)
+
+				assert.Error(t, err)
+				assert.Equal(t, 400, response.statusCode)
+
+				request.AssertExpectations(t)
+				response.AssertExpectations(t)
+			})
+		}
+	})
 }
 
 func TestPacksAPI_ListPacks(t *testing.T) {
@@ -323,18 +578,111 @@ func TestPacksAPI_ListPacks(t *testing.T) {
 			},
 		}
 
-		mockStore.EXPECT().ListPacks(gomock.Any()).Return(expectedPacks, nil)
-		response.On("OK", expectedPacks).Return(nil)
+		mockListParameters(request, nil)
+		mockStore.EXPECT().ListPacks(gomock.Any(), store.PackQuery{}).
+			Return(&store.PackPage{Packs: expectedPacks, NextCursor: "next"}, nil)
+		response.On("OK", mock.Anything).Return(nil)
 
 		err := api.ListPacks(ctx, request, response)
 
 		require.NoError(t, err)
 		assert.Equal(t, 200, response.statusCode)
-		assert.Equal(t, expectedPacks, response.data)
+		assert.Equal(t, model.ListPacksResponse{Packs: expectedPacks, NextCursor: "next"}, response.data)
 
 		response.AssertExpectations(t)
 	})
 
+	t.Run("filters and order", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		mockListParameters(request, map[string]string{
+			"created_after":    "2024-01-01T00:00:00Z",
+			"created_before":   "2024-02-01T00:00:00Z",
+			"contains_size":    "250",
+			"min_total_amount": "1000",
+			"max_total_amount": "5000",
+			"hash_prefix":      "ab",
+			"sort":             "total_amount",
+			"order":            "desc",
+			"limit":            "10",
+			"cursor":           "cursor",
+		})
+		mockStore.EXPECT().ListPacks(gomock.Any(), store.PackQuery{
+			CreatedAfter:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedBefore:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			ContainsSize:   250,
+			MinTotalAmount: 1000,
+			MaxTotalAmount: 5000,
+			HashPrefix:     "ab",
+			SortBy:         store.SortByTotalAmount,
+			Descending:     true,
+			Limit:          10,
+			Cursor:         "cursor",
+		}).Return(&store.PackPage{Packs: []model.Pack{}}, nil)
+		response.On("OK", mock.Anything).Return(nil)
+
+		err := api.ListPacks(ctx, request, response)
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.statusCode)
+		assert.Equal(t, model.ListPacksResponse{Packs: []model.Pack{}}, response.data)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for name, values := range map[string]map[string]string{
+			"created after":   {"created_after": "yesterday"},
+			"contains size":   {"contains_size": "-1"},
+			"total amount":    {"min_total_amount": "lots"},
+			"sort":            {"sort": "version_hash"},
+			"order":           {"order": "up"},
+			"zero limit":      {"limit": "0"},
+			"limit too large": {"limit": "501"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockStore := mock_store.NewMockStore(gomock.NewController(t))
+				api := NewPacksAPI(mockStore)
+
+				request := &MockRequest{}
+				response := &MockResponse{}
+
+				expectedError := errors.New("bad request")
+
+				mockListParameters(request, values)
+				response.On("BadRequest", "invalid query: %s", mock.Anything).Return(expectedError)
+
+				err := api.ListPacks(context.Background(), request, response)
+
+				assert.Equal(t, expectedError, err)
+				assert.Equal(t, 400, response.statusCode)
+			})
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		api := NewPacksAPI(mockStore)
+		ctx := context.Background()
+
+		request := &MockRequest{}
+		response := &MockResponse{}
+
+		expectedError := errors.New("bad request")
+
+		mockListParameters(request, map[string]string{"cursor": "garbage"})
+		mockStore.EXPECT().ListPacks(gomock.Any(), gomock.Any()).Return(nil, store.ErrInvalidQuery)
+		response.On("BadRequest", "invalid query: %s", mock.Anything).Return(expectedError)
+
+		err := api.ListPacks(ctx, request, response)
+
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, 400, response.statusCode)
+	})
+
 	t.Run("service error", func(t *testing.T) {
 		mockStore := mock_store.NewMockStore(gomock.NewController(t))
 		api := NewPacksAPI(mockStore)
@@ -344,7 +692,8 @@ func TestPacksAPI_ListPacks(t *testing.T) {
 		response := &MockResponse{}
 
 		expectedError := errors.New("database error")
-		mockStore.EXPECT().ListPacks(gomock.Any()).Return(nil, expectedError)
+		mockListParameters(request, nil)
+		mockStore.EXPECT().ListPacks(gomock.Any(), gomock.Any()).Return(nil, expectedError)
 		response.On("InternalServerError", "can't list packs: %s", mock.Anything).Return(expectedError)
 
 		err := api.ListPacks(ctx, request, response)
@@ -356,6 +705,15 @@ func TestPacksAPI_ListPacks(t *testing.T) {
 	})
 }
 
+// mockListParameters mocks optional parameters of pack listings, unset ones are empty.
+func mockListParameters(request *MockRequest, values map[string]string) {
+	var keys = []string{"created_after", "created_before", "contains_size", "min_total_amount", "max_total_amount",
+		"hash_prefix", "sort", "order", "limit", "cursor"}
+	for _, key := range keys {
+		request.On("String", key, mock.Anything).Return(values[key]).Maybe()
+	}
+}
+
 func TestPacksAPI_GetPackByID(t *testing.T) {
 	t.Run("successful retrieval", func(t *testing.T) {
 		mockStore := mock_store.NewMockStore(gomock.NewController(t))
@@ -533,8 +891,8 @@ func TestPacksAPI_ContextHandling(t *testing.T) {
 
 		// Mock the behavior for canceled context
 		request.On("Body").Return(&model.CreatePacksRequest{Packs: []int64{250}})
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(context.Canceled)
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(false, context.Canceled)
 		response.On("InternalServerError", "can't create packs: %s", mock.Anything).Return(context.Canceled)
 
 		err := api.CreatePacks(ctx, request, response)
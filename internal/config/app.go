@@ -5,13 +5,16 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // AppConfig holds the complete application configuration
 type AppConfig struct {
-	Server   ServerConfig      // HTTP server configuration
-	Database DatabaseConfig    // Database connection configuration
-	App      ApplicationConfig // Application-specific settings
+	Server     ServerConfig      // HTTP server configuration
+	Database   DatabaseConfig    // Database connection configuration
+	App        ApplicationConfig // Application-specific settings
+	Calculator CalculatorConfig  // Pack calculation settings
+	Admin      AdminConfig       // Administration endpoints settings
 }
 
 // ServerConfig contains HTTP server settings
@@ -27,6 +30,21 @@ type ApplicationConfig struct {
 	Debug       bool   // Debug mode flag
 }
 
+// CalculatorConfig contains pack calculation settings
+type CalculatorConfig struct {
+	CacheSize    int64         // Memory limit for cached calculation tables in bytes
+	Budget       time.Duration // Time limit of a single calculation, zero if unlimited
+	MaxAmount    int64         // Largest amount of a calculation
+	MaxPackSizes int           // Most distinct pack sizes of a calculation
+	MaxMemory    int64         // Memory limit for tables of a single calculation in bytes
+}
+
+// AdminConfig contains administration endpoints settings
+type AdminConfig struct {
+	APIKey    string        // Key authorizing administration requests, endpoints are disabled if empty
+	Retention time.Duration // How long deleted pack configurations are kept before they can be purged
+}
+
 // NewAppConfig creates a new application configuration by loading values
 // from environment variables with fallback to default values.
 func NewAppConfig() (*AppConfig, error) {
@@ -42,24 +60,74 @@ func NewAppConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("invalid DEBUG value: %w", err)
 	}
 
+	// Parse calculation cache size from environment variable
+	cacheSize, err := strconv.ParseInt(getEnv("CALC_CACHE_SIZE_MB", "64"), 10, 64)
+	if err != nil || cacheSize < 0 {
+		return nil, fmt.Errorf("invalid CALC_CACHE_SIZE_MB value: %q", getEnv("CALC_CACHE_SIZE_MB", "64"))
+	}
+
+	// Parse calculation time budget from environment variable
+	budget, err := strconv.ParseInt(getEnv("CALC_BUDGET_MS", "10000"), 10, 64)
+	if err != nil || budget < 0 {
+		return nil, fmt.Errorf("invalid CALC_BUDGET_MS value: %q", getEnv("CALC_BUDGET_MS", "10000"))
+	}
+
+	// Parse calculation input limits from environment variables
+	maxAmount, err := strconv.ParseInt(getEnv("CALC_MAX_AMOUNT", "1000000000000"), 10, 64)
+	if err != nil || maxAmount <= 0 {
+		return nil, fmt.Errorf("invalid CALC_MAX_AMOUNT value: %q", getEnv("CALC_MAX_AMOUNT", "1000000000000"))
+	}
+
+	maxPackSizes, err := strconv.Atoi(getEnv("CALC_MAX_PACK_SIZES", "100"))
+	if err != nil || maxPackSizes <= 0 || maxPackSizes > 255 {
+		return nil, fmt.Errorf("invalid CALC_MAX_PACK_SIZES value: %q", getEnv("CALC_MAX_PACK_SIZES", "100"))
+	}
+
+	maxMemory, err := strconv.ParseInt(getEnv("CALC_MAX_MEMORY_MB", "1024"), 10, 64)
+	if err != nil || maxMemory <= 0 {
+		return nil, fmt.Errorf("invalid CALC_MAX_MEMORY_MB value: %q", getEnv("CALC_MAX_MEMORY_MB", "1024"))
+	}
+
+	// Parse retention period of deleted pack configurations from environment variable
+	retention, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || retention < 0 {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS value: %q", getEnv("TRASH_RETENTION_DAYS", "30"))
+	}
+
+	// Load database settings and check the driver is supported
+	database := NewDatabaseConfig()
+	if err := database.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Parse development schema migration flag from environment variable
+	database.AutoMigrate, err = strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_AUTO_MIGRATE value: %w", err)
+	}
+
 	return &AppConfig{
 		Server: ServerConfig{
 			Host: getEnv("HOST", "0.0.0.0"),
 			Port: port,
 		},
-		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Database: getEnv("DB_NAME", "packulator"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-		},
+		Database: *database,
 		App: ApplicationConfig{
 			Environment: getEnv("ENVIRONMENT", "development"),
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
 			Debug:       debug,
 		},
+		Calculator: CalculatorConfig{
+			CacheSize:    cacheSize << 20,
+			Budget:       time.Duration(budget) * time.Millisecond,
+			MaxAmount:    maxAmount,
+			MaxPackSizes: maxPackSizes,
+			MaxMemory:    maxMemory << 20,
+		},
+		Admin: AdminConfig{
+			APIKey:    getEnv("ADMIN_API_KEY", ""),
+			Retention: time.Duration(retention) * 24 * time.Hour,
+		},
 	}, nil
 }
 
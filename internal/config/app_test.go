@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,9 +13,11 @@ func TestNewAppConfig(t *testing.T) {
 	t.Run("default values", func(t *testing.T) {
 		// Clear environment variables
 		envVars := []string{
-			"HOST", "PORT", "DB_HOST", "DB_PORT", "DB_USER",
+			"HOST", "PORT", "DB_DRIVER", "DB_PATH", "DB_AUTO_MIGRATE", "DB_HOST", "DB_PORT", "DB_USER",
 			"DB_PASSWORD", "DB_NAME", "DB_SSL_MODE", "ENVIRONMENT",
-			"LOG_LEVEL", "DEBUG",
+			"LOG_LEVEL", "DEBUG", "CALC_CACHE_SIZE_MB", "CALC_BUDGET_MS",
+			"CALC_MAX_AMOUNT", "CALC_MAX_PACK_SIZES", "CALC_MAX_MEMORY_MB",
+			"ADMIN_API_KEY", "TRASH_RETENTION_DAYS",
 		}
 		for _, env := range envVars {
 			os.Unsetenv(env)
@@ -28,6 +31,9 @@ func TestNewAppConfig(t *testing.T) {
 		assert.Equal(t, 8080, cfg.Server.Port)
 
 		// Database defaults
+		assert.Equal(t, "postgres", cfg.Database.Driver)
+		assert.Equal(t, "packulator.db", cfg.Database.Path)
+		assert.False(t, cfg.Database.AutoMigrate)
 		assert.Equal(t, "localhost", cfg.Database.Host)
 		assert.Equal(t, "5432", cfg.Database.Port)
 		assert.Equal(t, "postgres", cfg.Database.User)
@@ -39,12 +45,26 @@ func TestNewAppConfig(t *testing.T) {
 		assert.Equal(t, "development", cfg.App.Environment)
 		assert.Equal(t, "info", cfg.App.LogLevel)
 		assert.False(t, cfg.App.Debug)
+
+		// Calculator defaults
+		assert.Equal(t, int64(64<<20), cfg.Calculator.CacheSize)
+		assert.Equal(t, 10*time.Second, cfg.Calculator.Budget)
+		assert.Equal(t, int64(1_000_000_000_000), cfg.Calculator.MaxAmount)
+		assert.Equal(t, 100, cfg.Calculator.MaxPackSizes)
+		assert.Equal(t, int64(1024<<20), cfg.Calculator.MaxMemory)
+
+		// Admin defaults
+		assert.Empty(t, cfg.Admin.APIKey)
+		assert.Equal(t, 30*24*time.Hour, cfg.Admin.Retention)
 	})
 
 	t.Run("custom environment variables", func(t *testing.T) {
 		// Set custom environment variables
 		os.Setenv("HOST", "127.0.0.1")
 		os.Setenv("PORT", "3000")
+		os.Setenv("DB_DRIVER", "sqlite")
+		os.Setenv("DB_PATH", "/tmp/packulator.db")
+		os.Setenv("DB_AUTO_MIGRATE", "true")
 		os.Setenv("DB_HOST", "db.example.com")
 		os.Setenv("DB_PORT", "5433")
 		os.Setenv("DB_USER", "testuser")
@@ -54,12 +74,21 @@ func TestNewAppConfig(t *testing.T) {
 		os.Setenv("ENVIRONMENT", "production")
 		os.Setenv("LOG_LEVEL", "debug")
 		os.Setenv("DEBUG", "true")
+		os.Setenv("CALC_CACHE_SIZE_MB", "16")
+		os.Setenv("CALC_BUDGET_MS", "250")
+		os.Setenv("CALC_MAX_AMOUNT", "5000000")
+		os.Setenv("CALC_MAX_PACK_SIZES", "20")
+		os.Setenv("CALC_MAX_MEMORY_MB", "256")
+		os.Setenv("ADMIN_API_KEY", "secret")
+		os.Setenv("TRASH_RETENTION_DAYS", "7")
 
 		defer func() {
 			envVars := []string{
-				"HOST", "PORT", "DB_HOST", "DB_PORT", "DB_USER",
+				"HOST", "PORT", "DB_DRIVER", "DB_PATH", "DB_AUTO_MIGRATE", "DB_HOST", "DB_PORT", "DB_USER",
 				"DB_PASSWORD", "DB_NAME", "DB_SSL_MODE", "ENVIRONMENT",
-				"LOG_LEVEL", "DEBUG",
+				"LOG_LEVEL", "DEBUG", "CALC_CACHE_SIZE_MB", "CALC_BUDGET_MS",
+				"CALC_MAX_AMOUNT", "CALC_MAX_PACK_SIZES", "CALC_MAX_MEMORY_MB",
+				"ADMIN_API_KEY", "TRASH_RETENTION_DAYS",
 			}
 			for _, env := range envVars {
 				os.Unsetenv(env)
@@ -74,6 +103,9 @@ func TestNewAppConfig(t *testing.T) {
 		assert.Equal(t, 3000, cfg.Server.Port)
 
 		// Database custom values
+		assert.Equal(t, "sqlite", cfg.Database.Driver)
+		assert.Equal(t, "/tmp/packulator.db", cfg.Database.Path)
+		assert.True(t, cfg.Database.AutoMigrate)
 		assert.Equal(t, "db.example.com", cfg.Database.Host)
 		assert.Equal(t, "5433", cfg.Database.Port)
 		assert.Equal(t, "testuser", cfg.Database.User)
@@ -85,6 +117,17 @@ func TestNewAppConfig(t *testing.T) {
 		assert.Equal(t, "production", cfg.App.Environment)
 		assert.Equal(t, "debug", cfg.App.LogLevel)
 		assert.True(t, cfg.App.Debug)
+
+		// Calculator custom values
+		assert.Equal(t, int64(16<<20), cfg.Calculator.CacheSize)
+		assert.Equal(t, 250*time.Millisecond, cfg.Calculator.Budget)
+		assert.Equal(t, int64(5_000_000), cfg.Calculator.MaxAmount)
+		assert.Equal(t, 20, cfg.Calculator.MaxPackSizes)
+		assert.Equal(t, int64(256<<20), cfg.Calculator.MaxMemory)
+
+		// Admin custom values
+		assert.Equal(t, "secret", cfg.Admin.APIKey)
+		assert.Equal(t, 7*24*time.Hour, cfg.Admin.Retention)
 	})
 
 	t.Run("invalid PORT value", func(t *testing.T) {
@@ -106,6 +149,75 @@ func TestNewAppConfig(t *testing.T) {
 		assert.Nil(t, cfg)
 		assert.Contains(t, err.Error(), "invalid DEBUG value")
 	})
+
+	t.Run("invalid CALC_CACHE_SIZE_MB value", func(t *testing.T) {
+		os.Setenv("CALC_CACHE_SIZE_MB", "-1")
+		defer os.Unsetenv("CALC_CACHE_SIZE_MB")
+
+		cfg, err := NewAppConfig()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "invalid CALC_CACHE_SIZE_MB value")
+	})
+
+	t.Run("invalid CALC_BUDGET_MS value", func(t *testing.T) {
+		os.Setenv("CALC_BUDGET_MS", "1s")
+		defer os.Unsetenv("CALC_BUDGET_MS")
+
+		cfg, err := NewAppConfig()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "invalid CALC_BUDGET_MS value")
+	})
+
+	t.Run("invalid TRASH_RETENTION_DAYS value", func(t *testing.T) {
+		os.Setenv("TRASH_RETENTION_DAYS", "-1")
+		defer os.Unsetenv("TRASH_RETENTION_DAYS")
+
+		cfg, err := NewAppConfig()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "invalid TRASH_RETENTION_DAYS value")
+	})
+
+	t.Run("invalid database driver", func(t *testing.T) {
+		os.Setenv("DB_DRIVER", "mysql")
+		defer os.Unsetenv("DB_DRIVER")
+
+		cfg, err := NewAppConfig()
+
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "invalid DB_DRIVER value")
+	})
+
+	t.Run("invalid auto migrate flag", func(t *testing.T) {
+		os.Setenv("DB_AUTO_MIGRATE", "sometimes")
+		defer os.Unsetenv("DB_AUTO_MIGRATE")
+
+		cfg, err := NewAppConfig()
+
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "invalid DB_AUTO_MIGRATE value")
+	})
+
+	t.Run("invalid calculation input limits", func(t *testing.T) {
+		for env, value := range map[string]string{
+			"CALC_MAX_AMOUNT":     "0",
+			"CALC_MAX_PACK_SIZES": "256",
+			"CALC_MAX_MEMORY_MB":  "many",
+		} {
+			os.Setenv(env, value)
+
+			cfg, err := NewAppConfig()
+			assert.Error(t, err)
+			assert.Nil(t, cfg)
+			assert.Contains(t, err.Error(), "invalid "+env+" value")
+
+			os.Unsetenv(env)
+		}
+	})
 }
 
 func TestAppConfig_ServerAddress(t *testing.T) {
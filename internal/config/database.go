@@ -5,20 +5,33 @@ import (
 	"os"
 )
 
-// DatabaseConfig holds PostgreSQL database connection settings
+// Supported database drivers
+const (
+	DriverPostgres = "postgres" // PostgreSQL server
+	DriverSQLite   = "sqlite"   // SQLite database file
+	DriverMemory   = "memory"   // In-memory store, lost on exit
+)
+
+// DatabaseConfig holds database driver and connection settings
 type DatabaseConfig struct {
+	Driver   string // Database driver: postgres, sqlite or memory
+	Path     string // SQLite database file path
 	Host     string // Database host address
 	Port     string // Database port number
 	User     string // Database username
 	Password string // Database password
 	Database string // Database name
 	SSLMode  string // SSL connection mode
+
+	AutoMigrate bool // Migrate the schema by GORM's AutoMigrate instead of versioned migrations, development only
 }
 
 // NewDatabaseConfig creates a new database configuration with values
 // loaded from environment variables and sensible defaults.
 func NewDatabaseConfig() *DatabaseConfig {
 	return &DatabaseConfig{
+		Driver:   getEnv("DB_DRIVER", DriverPostgres),
+		Path:     getEnv("DB_PATH", "packulator.db"),
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnv("DB_PORT", "5432"),
 		User:     getEnv("DB_USER", "postgres"),
@@ -35,6 +48,16 @@ func (c *DatabaseConfig) DSN() string {
 		c.Host, c.Port, c.User, c.Password, c.Database, c.SSLMode)
 }
 
+// Validate checks that the database driver is supported.
+func (c *DatabaseConfig) Validate() error {
+	switch c.Driver {
+	case DriverPostgres, DriverSQLite, DriverMemory:
+		return nil
+	default:
+		return fmt.Errorf("invalid DB_DRIVER value: %q", c.Driver)
+	}
+}
+
 // getEnv retrieves an environment variable value or returns a default value
 // if the environment variable is not set or is empty.
 func getEnv(key, defaultValue string) string {
@@ -10,13 +10,15 @@ import (
 func TestNewDatabaseConfig(t *testing.T) {
 	t.Run("default values", func(t *testing.T) {
 		// Clear database environment variables
-		envVars := []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE"}
+		envVars := []string{"DB_DRIVER", "DB_PATH", "DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE"}
 		for _, env := range envVars {
 			os.Unsetenv(env)
 		}
 
 		cfg := NewDatabaseConfig()
 
+		assert.Equal(t, DriverPostgres, cfg.Driver)
+		assert.Equal(t, "packulator.db", cfg.Path)
 		assert.Equal(t, "localhost", cfg.Host)
 		assert.Equal(t, "5432", cfg.Port)
 		assert.Equal(t, "postgres", cfg.User)
@@ -75,6 +77,16 @@ func TestNewDatabaseConfig(t *testing.T) {
 	})
 }
 
+func TestDatabaseConfig_Validate(t *testing.T) {
+	for _, driver := range []string{DriverPostgres, DriverSQLite, DriverMemory} {
+		cfg := DatabaseConfig{Driver: driver}
+		assert.NoError(t, cfg.Validate(), driver)
+	}
+
+	cfg := DatabaseConfig{Driver: "mysql"}
+	assert.EqualError(t, cfg.Validate(), `invalid DB_DRIVER value: "mysql"`)
+}
+
 func TestDatabaseConfig_DSN(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1,14 +1,274 @@
 // Package model contains data transfer objects (DTOs) for API communication.
 package model
 
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
 // CreatePacksRequest represents the payload for creating a new pack configuration.
-// It contains an array of pack sizes that will be available for packaging calculations.
+// It contains an array of pack sizes that will be available for packaging calculations
+// and optional unit costs, weights, volumes and quantity rules of packs keyed by their sizes.
 type CreatePacksRequest struct {
-	Packs []int64 `json:"packs"` // Array of available pack sizes
+	Packs   []int64                `json:"packs"`             // Array of available pack sizes
+	Costs   map[int64]int64        `json:"costs,omitempty"`   // Unit costs of packs by size
+	Weights map[int64]int64        `json:"weights,omitempty"` // Weights of single packs by size
+	Volumes map[int64]int64        `json:"volumes,omitempty"` // Volumes of single packs by size
+	Rules   map[int64]QuantityRule `json:"rules,omitempty"`   // Rules of numbers of packs by size
+}
+
+// QuantityRule restricts the number of packs of a size in a combination, zero fields don't restrict it.
+type QuantityRule struct {
+	MinCount int64 `json:"min_count,omitempty"` // Fewest packs if the size is used
+	MaxCount int64 `json:"max_count,omitempty"` // Most packs
+	Step     int64 `json:"step,omitempty"`      // Number of packs must be its multiple
 }
 
 // CreatePacksResponse represents the response after creating a pack configuration.
-// It returns the version hash that can be used to reference this pack configuration.
+// It returns the version hash that can be used to reference this pack configuration
+// and whether the configuration was created or already existed.
 type CreatePacksResponse struct {
 	VersionHash string `json:"version_hash"` // Unique hash identifying the pack configuration
+	Created     bool   `json:"created"`      // Whether the configuration was created by the request
+}
+
+// ListPacksResponse represents a page of pack configurations.
+// The next page is requested with the cursor, which is empty on the last page.
+type ListPacksResponse struct {
+	Packs      []Pack `json:"packs"`                 // Pack configurations of the page
+	NextCursor string `json:"next_cursor,omitempty"` // Cursor of the next page
+}
+
+// DeletedPack represents a deleted pack configuration which can be restored until it's purged.
+type DeletedPack struct {
+	Pack
+	DeletedAt time.Time `json:"deleted_at"` // Timestamp when the pack was deleted
+}
+
+// ListDeletedPacksResponse represents deleted pack configurations, the most recently deleted first.
+type ListDeletedPacksResponse struct {
+	Packs []DeletedPack `json:"packs"` // Deleted pack configurations
+}
+
+// PurgePacksResponse represents the result of permanently deleting old deleted pack configurations.
+type PurgePacksResponse struct {
+	Purged        int64     `json:"purged"`         // Number of purged pack configurations
+	DeletedBefore time.Time `json:"deleted_before"` // Packs deleted before the time were purged
+}
+
+// BatchCalculationRequest represents the payload for calculating packs of many orders at once.
+type BatchCalculationRequest struct {
+	Items []BatchCalculationItem `json:"items"` // Orders to calculate
+}
+
+// BatchCalculationItem is a single order of a batch calculation.
+type BatchCalculationItem struct {
+	Amount    int64  `json:"amount"`     // Amount to be packed
+	PacksHash string `json:"packs_hash"` // Pack configuration hash
+}
+
+// BatchCalculationResponse represents results of a batch calculation
+// in the order of requested items.
+type BatchCalculationResponse struct {
+	Results []BatchCalculationResult `json:"results"` // Results by item
+}
+
+// BatchCalculationResult is the outcome of a single order of a batch calculation.
+// Either packs or an error is set.
+type BatchCalculationResult struct {
+	Amount    int64           `json:"amount"`          // Requested amount
+	PacksHash string          `json:"packs_hash"`      // Requested pack configuration hash
+	Packs     map[int64]int64 `json:"packs,omitempty"` // Number of packs by size
+	Error     string          `json:"error,omitempty"` // Reason the item couldn't be calculated
+}
+
+// CalculationExplanation represents an explained calculation result: the chosen combination
+// and runner-up combinations ranked next by the strategy.
+type CalculationExplanation struct {
+	Strategy     string                 `json:"strategy"`     // Strategy ranking combinations
+	Chosen       CalculationCandidate   `json:"chosen"`       // Optimal combination
+	Alternatives []CalculationCandidate `json:"alternatives"` // Runner-up combinations from the best one
+}
+
+// CalculationParetoFront represents combinations of packs not dominated by overshoot
+// and number of packs, ordered by overshoot from the least one.
+type CalculationParetoFront struct {
+	RequestedAmount int64                  `json:"requested_amount"` // Amount to be packed
+	VersionHash     string                 `json:"version_hash"`     // Pack configuration hash
+	Front           []CalculationCandidate `json:"front"`            // Non-dominated combinations
+}
+
+// CalculationCandidate represents a combination of packs with its measures.
+type CalculationCandidate struct {
+	Packs         map[int64]int64 `json:"packs"`           // Number of packs by size
+	Shipped       int64           `json:"shipped"`         // Total items shipped
+	Overshoot     int64           `json:"overshoot"`       // Items above the amount, negative if fewer are shipped
+	NumberOfPacks int64           `json:"number_of_packs"` // Number of packs
+	Cost          int64           `json:"cost"`            // Total cost of packs
+}
+
+// CalculationResponse represents the result of a pack calculation.
+type CalculationResponse struct {
+	Lines           []PackLine `json:"lines"`               // Packs to ship ordered by size
+	RequestedAmount int64      `json:"requested_amount"`    // Amount to be packed
+	ShippedAmount   int64      `json:"shipped_amount"`      // Total items shipped
+	Overshoot       int64      `json:"overshoot"`           // Items above the amount, negative if fewer are shipped
+	TotalPacks      int64      `json:"total_packs"`         // Number of packs
+	VersionHash     string     `json:"version_hash"`        // Pack configuration hash
+	Algorithm       string     `json:"algorithm"`           // Strategy the combination was chosen by
+	Shipments       []Shipment `json:"shipments,omitempty"` // Packs split by shipment limits if requested
+	Parcels         []Parcel   `json:"parcels,omitempty"`   // Packs split into parcels if requested
+}
+
+// InfeasibleResponse reports that no combination covering the amount fits the maximum number of packs.
+type InfeasibleResponse struct {
+	Error    string `json:"error"`     // Description of the failure
+	MaxPacks int64  `json:"max_packs"` // Largest number of packs allowed
+	MinPacks int64  `json:"min_packs"` // Fewest packs covering the amount, zero if unknown
+}
+
+// Shipment is a group of identical shipments within weight and volume limits.
+type Shipment struct {
+	Lines  []PackLine `json:"lines"`  // Packs of every shipment ordered by size
+	Weight int64      `json:"weight"` // Total weight of every shipment
+	Volume int64      `json:"volume"` // Total volume of every shipment
+	Count  int64      `json:"count"`  // Number of identical shipments
+}
+
+// Parcel is a group of consecutive identical parcels, numbered from one.
+type Parcel struct {
+	Lines []PackLine `json:"lines"` // Packs of every parcel ordered by size
+	Packs int64      `json:"packs"` // Number of packs in every parcel
+	First int64      `json:"first"` // Number of the first parcel of the group
+	Count int64      `json:"count"` // Number of identical parcels
+}
+
+// PackLine is the number of packs of a single size.
+type PackLine struct {
+	Size  int64 `json:"size"`  // Size of the pack
+	Count int64 `json:"count"` // Number of packs
+}
+
+// NewPackLines lists the combination of packs ordered by size.
+func NewPackLines(combination map[int64]int64) []PackLine {
+	var lines = make([]PackLine, 0, len(combination))
+	for size, count := range combination {
+		lines = append(lines, PackLine{Size: size, Count: count})
+	}
+
+	slices.SortFunc(lines, func(a, b PackLine) int {
+		return cmp.Compare(a.Size, b.Size)
+	})
+
+	return lines
+}
+
+// NewCalculationResponse builds the response for the combination of packs calculated
+// for the amount with the pack configuration and the algorithm.
+func NewCalculationResponse(
+	amount int64,
+	versionHash, algorithm string,
+	combination map[int64]int64,
+) CalculationResponse {
+	var response = CalculationResponse{
+		Lines:           NewPackLines(combination),
+		RequestedAmount: amount,
+		VersionHash:     versionHash,
+		Algorithm:       algorithm,
+	}
+
+	for size, count := range combination {
+		response.ShippedAmount += size * count
+		response.TotalPacks += count
+	}
+
+	response.Overshoot = response.ShippedAmount - amount
+
+	return response
+}
+
+// OrderRequest represents an order of several products to be packed together.
+type OrderRequest struct {
+	Lines []OrderLine `json:"lines"` // Products of the order
+}
+
+// OrderLine is a single product of an order.
+type OrderLine struct {
+	SKU       string `json:"sku"`        // Product identifier
+	Amount    int64  `json:"amount"`     // Amount of the product to be packed
+	PacksHash string `json:"packs_hash"` // Pack configuration hash of the product
+}
+
+// OrderResponse represents a consolidated shipment of an order.
+type OrderResponse struct {
+	Lines      []OrderLineResult `json:"lines"`       // Calculations by order line
+	Totals     []PackLine        `json:"totals"`      // Packs of all lines by size ordered by size
+	TotalPacks int64             `json:"total_packs"` // Number of packs in the shipment
+}
+
+// OrderLineResult is the calculation of a single product of an order.
+type OrderLineResult struct {
+	SKU string `json:"sku"` // Product identifier
+
+	CalculationResponse
+}
+
+// NewOrderResponse builds the shipment of order lines summing up their packs by size.
+func NewOrderResponse(lines []OrderLineResult) OrderResponse {
+	var (
+		response = OrderResponse{Lines: lines, Totals: []PackLine{}}
+		totals   = make(map[int64]int64)
+	)
+
+	for _, line := range lines {
+		for _, pack := range line.Lines {
+			totals[pack.Size] += pack.Count
+		}
+
+		response.TotalPacks += line.TotalPacks
+	}
+
+	for size, count := range totals {
+		response.Totals = append(response.Totals, PackLine{Size: size, Count: count})
+	}
+
+	slices.SortFunc(response.Totals, func(a, b PackLine) int {
+		return cmp.Compare(a.Size, b.Size)
+	})
+
+	return response
+}
+
+// CreateHierarchyRequest represents the payload for creating a packaging hierarchy.
+type CreateHierarchyRequest struct {
+	Name   string                 `json:"name"`   // Human readable name of the hierarchy
+	Levels []CreateHierarchyLevel `json:"levels"` // Levels from the innermost one
+}
+
+// CreateHierarchyLevel is a single level of a created packaging hierarchy.
+type CreateHierarchyLevel struct {
+	Name      string `json:"name"`       // Name of containers of the level, e.g. "carton"
+	PacksHash string `json:"packs_hash"` // Hash of the configuration of container capacities
+}
+
+// CreateHierarchyResponse represents the response after creating a packaging hierarchy.
+type CreateHierarchyResponse struct {
+	ID string `json:"id"` // Unique identifier of the hierarchy
+}
+
+// HierarchyPlanResponse represents the nested packing plan of an amount with a hierarchy.
+type HierarchyPlanResponse struct {
+	HierarchyID     string       `json:"hierarchy_id"`     // Unique identifier of the hierarchy
+	RequestedAmount int64        `json:"requested_amount"` // Amount to be packed
+	Plan            *PackingPlan `json:"plan"`             // Packing from the outermost level
+}
+
+// PackingPlan is the packing of a single hierarchy level with the packing of its contents.
+type PackingPlan struct {
+	Level      string       `json:"level"`              // Name of containers of the level
+	Units      int64        `json:"units"`              // Units of the level below packed, items for the innermost level
+	Containers []PackLine   `json:"containers"`         // Containers by capacity ordered by capacity
+	Count      int64        `json:"count"`              // Total number of containers
+	Contents   *PackingPlan `json:"contents,omitempty"` // Packing of the level below
 }
@@ -42,6 +42,14 @@ func TestCreatePacksRequest_JSONMarshaling(t *testing.T) {
 			},
 			expected: `{"packs":[0,250,500]}`,
 		},
+		{
+			name: "packs with costs",
+			request: CreatePacksRequest{
+				Packs: []int64{250, 500},
+				Costs: map[int64]int64{250: 3},
+			},
+			expected: `{"packs":[250,500],"costs":{"250":3}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,6 +96,14 @@ func TestCreatePacksRequest_JSONUnmarshaling(t *testing.T) {
 				Packs: []int64{0, 250, 500},
 			},
 		},
+		{
+			name:     "packs with costs",
+			jsonData: `{"packs":[250,500],"costs":{"500":7}}`,
+			expected: CreatePacksRequest{
+				Packs: []int64{250, 500},
+				Costs: map[int64]int64{500: 7},
+			},
+		},
 		{
 			name:     "null packs field",
 			jsonData: `{"packs":null}`,
@@ -157,22 +173,23 @@ func TestCreatePacksResponse_JSONMarshaling(t *testing.T) {
 			name: "valid version hash",
 			response: CreatePacksResponse{
 				VersionHash: "abc123def456",
+				Created:     true,
 			},
-			expected: `{"version_hash":"abc123def456"}`,
+			expected: `{"version_hash":"abc123def456","created":true}`,
 		},
 		{
 			name: "empty version hash",
 			response: CreatePacksResponse{
 				VersionHash: "",
 			},
-			expected: `{"version_hash":""}`,
+			expected: `{"version_hash":"","created":false}`,
 		},
 		{
 			name: "long version hash",
 			response: CreatePacksResponse{
 				VersionHash: "1234567890abcdef1234567890abcdef12345678",
 			},
-			expected: `{"version_hash":"1234567890abcdef1234567890abcdef12345678"}`,
+			expected: `{"version_hash":"1234567890abcdef1234567890abcdef12345678","created":false}`,
 		},
 	}
 
@@ -194,9 +211,10 @@ func TestCreatePacksResponse_JSONUnmarshaling(t *testing.T) {
 	}{
 		{
 			name:     "valid version hash",
-			jsonData: `{"version_hash":"abc123def456"}`,
+			jsonData: `{"version_hash":"abc123def456","created":true}`,
 			expected: CreatePacksResponse{
 				VersionHash: "abc123def456",
+				Created:     true,
 			},
 		},
 		{
@@ -304,3 +322,141 @@ func TestCreatePacksResponse_RoundTrip(t *testing.T) {
 	// Should be equal to original
 	assert.Equal(t, original, unmarshaled)
 }
+
+func TestBatchCalculationRequest_JSONUnmarshaling(t *testing.T) {
+	var request BatchCalculationRequest
+
+	err := json.Unmarshal([]byte(`{"items":[{"amount":1001,"packs_hash":"abc123"},{"amount":5}]}`), &request)
+	require.NoError(t, err)
+
+	assert.Equal(t, BatchCalculationRequest{
+		Items: []BatchCalculationItem{
+			{Amount: 1001, PacksHash: "abc123"},
+			{Amount: 5},
+		},
+	}, request)
+}
+
+func TestBatchCalculationResponse_JSONMarshaling(t *testing.T) {
+	response := BatchCalculationResponse{
+		Results: []BatchCalculationResult{
+			{Amount: 1001, PacksHash: "abc123", Packs: map[int64]int64{250: 1, 1000: 1}},
+			{Amount: 5, PacksHash: "missing", Error: "packs not found by hash: missing"},
+		},
+	}
+
+	jsonData, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"results":[
+		{"amount":1001,"packs_hash":"abc123","packs":{"250":1,"1000":1}},
+		{"amount":5,"packs_hash":"missing","error":"packs not found by hash: missing"}
+	]}`, string(jsonData))
+}
+
+func TestCalculationExplanation_JSONMarshaling(t *testing.T) {
+	explanation := CalculationExplanation{
+		Strategy: "least_overshoot",
+		Chosen: CalculationCandidate{
+			Packs:         map[int64]int64{250: 1, 1000: 1},
+			Shipped:       1250,
+			Overshoot:     249,
+			NumberOfPacks: 2,
+		},
+		Alternatives: []CalculationCandidate{},
+	}
+
+	jsonData, err := json.Marshal(explanation)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"strategy":"least_overshoot",
+		"chosen":{"packs":{"250":1,"1000":1},"shipped":1250,"overshoot":249,"number_of_packs":2,"cost":0},
+		"alternatives":[]
+	}`, string(jsonData))
+}
+
+func TestNewCalculationResponse(t *testing.T) {
+	response := NewCalculationResponse(1001, "abc123", "least_overshoot", map[int64]int64{1000: 1, 250: 1})
+
+	assert.Equal(t, CalculationResponse{
+		Lines:           []PackLine{{Size: 250, Count: 1}, {Size: 1000, Count: 1}},
+		RequestedAmount: 1001,
+		ShippedAmount:   1250,
+		Overshoot:       249,
+		TotalPacks:      2,
+		VersionHash:     "abc123",
+		Algorithm:       "least_overshoot",
+	}, response)
+
+	jsonData, err := json.Marshal(NewCalculationResponse(0, "abc123", "least_overshoot", map[int64]int64{}))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"lines":[],
+		"requested_amount":0,
+		"shipped_amount":0,
+		"overshoot":0,
+		"total_packs":0,
+		"version_hash":"abc123",
+		"algorithm":"least_overshoot"
+	}`, string(jsonData))
+}
+
+func TestNewOrderResponse(t *testing.T) {
+	lines := []OrderLineResult{
+		{SKU: "bolt", CalculationResponse: NewCalculationResponse(1001, "bolts", "least_overshoot", map[int64]int64{250: 1, 1000: 1})},
+		{SKU: "nut", CalculationResponse: NewCalculationResponse(350, "nuts", "least_overshoot", map[int64]int64{100: 1, 250: 1})},
+	}
+
+	response := NewOrderResponse(lines)
+
+	assert.Equal(t, lines, response.Lines)
+	assert.Equal(t, []PackLine{{Size: 100, Count: 1}, {Size: 250, Count: 2}, {Size: 1000, Count: 1}}, response.Totals)
+	assert.Equal(t, int64(4), response.TotalPacks)
+
+	jsonData, err := json.Marshal(response.Lines[1])
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"sku":"nut",
+		"lines":[{"size":100,"count":1},{"size":250,"count":1}],
+		"requested_amount":350,
+		"shipped_amount":350,
+		"overshoot":0,
+		"total_packs":2,
+		"version_hash":"nuts",
+		"algorithm":"least_overshoot"
+	}`, string(jsonData))
+}
+
+func TestPackingPlan_JSONMarshaling(t *testing.T) {
+	plan := PackingPlan{
+		Level:      "carton",
+		Units:      13,
+		Containers: NewPackLines(map[int64]int64{10: 1, 4: 1}),
+		Count:      2,
+		Contents: &PackingPlan{
+			Level:      "pack",
+			Units:      12001,
+			Containers: NewPackLines(map[int64]int64{1000: 12, 250: 1}),
+			Count:      13,
+		},
+	}
+
+	jsonData, err := json.Marshal(plan)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"level":"carton",
+		"units":13,
+		"containers":[{"size":4,"count":1},{"size":10,"count":1}],
+		"count":2,
+		"contents":{
+			"level":"pack",
+			"units":12001,
+			"containers":[{"size":250,"count":1},{"size":1000,"count":12}],
+			"count":13
+		}
+	}`, string(jsonData))
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Hierarchy represents a multi-level packaging: items go into packs of the first level,
+// which go into containers of the next level and so on, e.g. packs into cartons onto pallets.
+type Hierarchy struct {
+	ID        string           `json:"id" gorm:"primaryKey"`                 // Unique identifier for the hierarchy
+	Name      string           `json:"name" gorm:"not null"`                 // Human readable name of the hierarchy
+	Levels    []HierarchyLevel `json:"levels" gorm:"foreignKey:HierarchyID"` // Levels from the innermost one
+	CreatedAt time.Time        `json:"created_at"`                           // Timestamp when hierarchy was created
+	UpdatedAt time.Time        `json:"updated_at"`                           // Timestamp when hierarchy was last updated
+	DeletedAt gorm.DeletedAt   `json:"-" gorm:"index"`                       // Soft delete timestamp
+}
+
+// HierarchyLevel represents a single level of a packaging hierarchy.
+// Sizes of its pack configuration are capacities of its containers in units
+// of the level below, items for the first level.
+type HierarchyLevel struct {
+	ID          string `json:"id" gorm:"primaryKey"`               // Unique identifier for the level
+	HierarchyID string `json:"hierarchy_id" gorm:"not null;index"` // Foreign key to the parent hierarchy
+	Position    int    `json:"position" gorm:"not null"`           // Zero-based position from the innermost level
+	Name        string `json:"name" gorm:"not null"`               // Name of containers of the level, e.g. "carton"
+	PacksHash   string `json:"packs_hash" gorm:"not null"`         // Version hash of the level's pack configuration
+}
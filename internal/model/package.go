@@ -11,21 +11,27 @@ import (
 // Pack represents a pack configuration with its associated pack sizes.
 // Each pack configuration has a unique version hash and contains multiple pack items.
 type Pack struct {
-	ID          string         `json:"id" gorm:"primaryKey"`                // Unique identifier for the pack
-	VersionHash string         `json:"version_hash" gorm:"index;not null"`  // Version hash for pack configuration
-	TotalAmount int64          `json:"total_amount" gorm:"not null"`        // Total amount that can be packed
-	PackItems   []PackItem     `json:"pack_items" gorm:"foreignKey:PackID"` // Associated pack items with sizes
-	CreatedAt   time.Time      `json:"created_at"`                          // Timestamp when pack was created
-	UpdatedAt   time.Time      `json:"updated_at"`                          // Timestamp when pack was last updated
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`                      // Soft delete timestamp
+	ID          string         `json:"id" gorm:"primaryKey"`                                                                            // Unique identifier for the pack
+	VersionHash string         `json:"version_hash" gorm:"not null;uniqueIndex:idx_packs_active_version_hash,where:deleted_at IS NULL"` // Version hash for pack configuration, unique among active packs
+	TotalAmount int64          `json:"total_amount" gorm:"not null"`                                                                    // Total amount that can be packed
+	PackItems   []PackItem     `json:"pack_items" gorm:"foreignKey:PackID"`                                                             // Associated pack items with sizes
+	CreatedAt   time.Time      `json:"created_at"`                                                                                      // Timestamp when pack was created
+	UpdatedAt   time.Time      `json:"updated_at"`                                                                                      // Timestamp when pack was last updated
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`                                                                                  // Soft delete timestamp
 }
 
 // PackItem represents an individual pack size within a pack configuration.
 // Multiple pack items belong to a single pack configuration.
 type PackItem struct {
-	ID     string `json:"id" gorm:"primaryKey"`          // Unique identifier for the pack item
-	PackID string `json:"pack_id" gorm:"not null;index"` // Foreign key to the parent pack
-	Size   int64  `json:"size" gorm:"not null"`          // Size of this pack item
+	ID       string `json:"id" gorm:"primaryKey"`                          // Unique identifier for the pack item
+	PackID   string `json:"pack_id" gorm:"not null;index"`                 // Foreign key to the parent pack
+	Size     int64  `json:"size" gorm:"not null"`                          // Size of this pack item
+	Cost     int64  `json:"cost,omitempty" gorm:"not null;default:0"`      // Unit cost of a single pack, zero if not set
+	Weight   int64  `json:"weight,omitempty" gorm:"not null;default:0"`    // Weight of a single pack, zero if not set
+	Volume   int64  `json:"volume,omitempty" gorm:"not null;default:0"`    // Volume of a single pack, zero if not set
+	MinCount int64  `json:"min_count,omitempty" gorm:"not null;default:0"` // Fewest packs in a combination using the size, zero if not set
+	MaxCount int64  `json:"max_count,omitempty" gorm:"not null;default:0"` // Most packs in a combination, zero if not set
+	Step     int64  `json:"step,omitempty" gorm:"not null;default:0"`      // Number of packs in a combination must be its multiple, zero if not set
 }
 
 // GetPacks extracts and returns all pack sizes from the pack items.
@@ -37,3 +43,12 @@ func (p *Pack) GetPacks() []int64 {
 	}
 	return packs
 }
+
+// GetCosts returns unit costs of pack items keyed by their sizes.
+func (p *Pack) GetCosts() map[int64]int64 {
+	costs := make(map[int64]int64, len(p.PackItems))
+	for _, item := range p.PackItems {
+		costs[item.Size] = item.Cost
+	}
+	return costs
+}
@@ -1,9 +1,11 @@
 package model
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPack_GetPacks(t *testing.T) {
@@ -172,6 +174,30 @@ func TestPack_GetPacks_EmptySliceNotNil(t *testing.T) {
 	assert.IsType(t, []int64{}, result, "GetPacks should return []int64 type")
 }
 
+func TestPack_GetCosts(t *testing.T) {
+	pack := Pack{
+		ID: "pack-1",
+		PackItems: []PackItem{
+			{ID: "item-1", PackID: "pack-1", Size: 250, Cost: 3},
+			{ID: "item-2", PackID: "pack-1", Size: 500},
+		},
+	}
+
+	assert.Equal(t, map[int64]int64{250: 3, 500: 0}, pack.GetCosts())
+}
+
+func TestPackItem_JSONOmitsUnsetFields(t *testing.T) {
+	jsonData, err := json.Marshal(PackItem{ID: "item-1", PackID: "pack-1", Size: 250})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"item-1","pack_id":"pack-1","size":250}`, string(jsonData))
+
+	jsonData, err = json.Marshal(PackItem{ID: "item-1", PackID: "pack-1", Size: 250, Cost: 3})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"item-1","pack_id":"pack-1","size":250,"cost":3}`, string(jsonData))
+}
+
 func TestPack_GetPacks_WithNilPackItems(t *testing.T) {
 	pack := Pack{
 		ID:          "pack-1",
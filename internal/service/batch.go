@@ -0,0 +1,51 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+)
+
+// NumberOfPacksBatch calculates combinations of the same packs for every amount.
+// Amounts are processed from the largest one, so a single table is built for all of them:
+// the shared cache is used if one is set, otherwise a table is kept for the batch only.
+// Options are applied once, like for NumberOfPacks, and shared by all amounts.
+// Results and errors are returned by the index of their amount, amounts left
+// when the context is done get its error.
+func NumberOfPacksBatch(
+	ctx context.Context,
+	amounts []int64,
+	packs []int64,
+	opts ...Option,
+) ([]map[int64]int64, []error) {
+	var options = newOptions(opts)
+	if options.cache == nil {
+		options.cache = NewTableCache(math.MaxInt64)
+	}
+
+	var order = make([]int, len(amounts))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(amounts[b], amounts[a])
+	})
+
+	var (
+		results = make([]map[int64]int64, len(amounts))
+		errs    = make([]error, len(amounts))
+	)
+
+	for _, i := range order {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		results[i], errs[i] = numberOfPacks(ctx, amounts[i], packs, options)
+	}
+
+	return results, errs
+}
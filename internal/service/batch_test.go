@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacksBatch(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		packs   = []int64{23, 31, 53}
+		amounts = []int64{500, 12, 0, 263, 5000, 500}
+	)
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithStrategy(FewestPacks()), WithTolerance(Tolerance{Items: 5}, Tolerance{})},
+		{WithStrategy(Cheapest()), WithCosts(map[int64]int64{23: 2, 31: 1, 53: 4}), WithMaxPacks(100)},
+	} {
+		results, errs := NumberOfPacksBatch(ctx, amounts, packs, opts...)
+
+		require.Len(t, results, len(amounts))
+		require.Len(t, errs, len(amounts))
+
+		for i, amount := range amounts {
+			expected, err := NumberOfPacks(ctx, amount, packs, opts...)
+			require.NoError(t, err)
+
+			assert.NoError(t, errs[i], "amount %d", amount)
+			assert.Equal(t, expected, results[i], "amount %d", amount)
+		}
+	}
+}
+
+func TestNumberOfPacksBatch_BuildsSingleTable(t *testing.T) {
+	cache := NewTableCache(1 << 20)
+
+	_, errs := NumberOfPacksBatch(context.Background(), []int64{100, 1500, 250}, []int64{23, 31, 53}, WithCache(cache))
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, cache.Len())
+
+	table, err := cache.table(defaultConfig(23, 31, 53), 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1553), table.maxRange(), "table should be built once for the largest amount")
+	assert.Equal(t, int64(1554), int64(cap(table.last)), "table shouldn't be extended")
+}
+
+func TestNumberOfPacksBatch_Errors(t *testing.T) {
+	t.Run("per amount errors", func(t *testing.T) {
+		results, errs := NumberOfPacksBatch(context.Background(), []int64{6, 11}, []int64{3, 5},
+			WithStrategy(WithinPacks(2)),
+		)
+
+		assert.NoError(t, errs[0])
+		assert.Equal(t, map[int64]int64{3: 2}, results[0])
+		assert.ErrorIs(t, errs[1], ErrNoCombination)
+		assert.Nil(t, results[1])
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, errs := NumberOfPacksBatch(ctx, []int64{6, 11}, []int64{3, 5})
+
+		for i := range errs {
+			assert.ErrorIs(t, errs[i], context.Canceled)
+			assert.Nil(t, results[i])
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+)
+
+// TableCache keeps calculation tables of pack configurations, so repeated requests
+// for the same configuration skip the dynamic programming. Tables are keyed by normalized
+// pack sizes along with costs and criteria they were computed for, grow
+// incrementally up to the largest amount seen and are evicted in least recently used
+// order once their total size exceeds the limit.
+type TableCache struct {
+	mu       sync.Mutex
+	maxBytes int64                    // Memory limit for all cached tables
+	used     int64                    // Memory occupied by cached tables
+	entries  map[string]*list.Element // Cached entries by key
+	order    *list.List               // Entries from the most to the least recently used
+}
+
+// cacheEntry is a single cached table.
+type cacheEntry struct {
+	mu    sync.Mutex // Serializes building and extending of the table
+	key   string     // Key of the entry in cache
+	table *table     // Latest version of the table, nil until built
+	size  int64      // Memory accounted for the table
+}
+
+// NewTableCache creates a cache keeping tables up to maxBytes in total.
+func NewTableCache(maxBytes int64) *TableCache {
+	return &TableCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Len returns the number of cached tables.
+func (c *TableCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Size returns the memory occupied by cached tables in bytes.
+func (c *TableCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.used
+}
+
+// table returns a table for the config covering sums up to maxRange,
+// building or extending the cached one if needed. If the calculation is stopped
+// meanwhile, the cached table stays as it was.
+// Returned table is never modified afterwards and is safe for concurrent reads.
+func (c *TableCache) table(config tableConfig, maxRange int64, stop *checkpoint) (*table, error) {
+	var element = c.element(config.key())
+
+	entry := element.Value.(*cacheEntry)
+	entry.mu.Lock()
+
+	var (
+		result = entry.table
+		err    error
+	)
+
+	switch {
+	case result == nil:
+		result, err = newTable(config, maxRange, stop)
+	case result.maxRange() < maxRange:
+		result, err = result.extended(maxRange, stop)
+	}
+
+	if err != nil {
+		entry.mu.Unlock()
+		return nil, err
+	}
+
+	entry.table = result
+	entry.mu.Unlock()
+
+	c.account(element, result.size())
+
+	return result, nil
+}
+
+// element returns the cache element by key creating it if absent
+// and marks it as the most recently used.
+func (c *TableCache) element(key string) *list.Element {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element
+	}
+
+	var element = c.order.PushFront(&cacheEntry{key: key})
+	c.entries[key] = element
+
+	return element
+}
+
+// account updates memory occupied by the element's table and evicts
+// least recently used tables while the limit is exceeded.
+func (c *TableCache) account(element *list.Element, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var entry = element.Value.(*cacheEntry)
+	if c.entries[entry.key] != element {
+		return // Evicted while the table was being built
+	}
+
+	c.used += size - entry.size
+	entry.size = size
+
+	for c.used > c.maxBytes && c.order.Len() > 0 {
+		var oldest = c.order.Back().Value.(*cacheEntry)
+
+		c.order.Remove(c.order.Back())
+		delete(c.entries, oldest.key)
+		c.used -= oldest.size
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultConfig returns the config of a table for packs compared by the default strategy.
+func defaultConfig(packs ...int64) tableConfig {
+	return tableConfig{packs: packs, criteria: sumCriteria(LeastOvershoot().Criteria())}
+}
+
+func TestTableCache_ReusesTable(t *testing.T) {
+	cache := NewTableCache(1 << 20)
+
+	first, err := cache.table(defaultConfig(1, 2, 4), 100, nil)
+	require.NoError(t, err)
+
+	second, err := cache.table(defaultConfig(1, 2, 4), 50, nil)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second, "smaller range should be served by the cached table")
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, first.size(), cache.Size())
+}
+
+func TestTableCache_ExtendsTable(t *testing.T) {
+	var (
+		cache = NewTableCache(1 << 20)
+		packs = []int64{23, 31, 53}
+	)
+
+	small, err := cache.table(defaultConfig(packs...), 100, nil)
+	require.NoError(t, err)
+
+	large, err := cache.table(defaultConfig(packs...), 5000, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), small.maxRange(), "previous table must stay untouched")
+	require.Equal(t, int64(5000), large.maxRange())
+
+	expected, err := newTable(defaultConfig(packs...), 5000, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.counts, large.counts)
+	assert.Equal(t, expected.last, large.last)
+	assert.Equal(t, large.size(), cache.Size())
+}
+
+func TestTableCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewTableCache(2500)
+
+	cache.table(defaultConfig(1, 2), 199, nil)
+	cache.table(defaultConfig(1, 3), 199, nil)
+	cache.table(defaultConfig(1, 2), 199, nil) // Touch first table
+	cache.table(defaultConfig(1, 4), 199, nil)
+
+	assert.Equal(t, 2, cache.Len())
+	assert.LessOrEqual(t, cache.Size(), int64(2500))
+	assert.Contains(t, cache.entries, defaultConfig(1, 2).key())
+	assert.Contains(t, cache.entries, defaultConfig(1, 4).key())
+	assert.NotContains(t, cache.entries, defaultConfig(1, 3).key())
+}
+
+func TestTableCache_TableLargerThanLimit(t *testing.T) {
+	cache := NewTableCache(10)
+
+	table, err := cache.table(defaultConfig(1, 2), 1000, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000), table.maxRange(), "table is still returned to the caller")
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, int64(0), cache.Size())
+}
+
+func TestNumberOfPacks_WithCache(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		cache = NewTableCache(1 << 20)
+		packs = []int64{23, 31, 53}
+	)
+
+	for _, amount := range []int64{100, 2000, 500, 1_000_000, 1999} {
+		expected, err := NumberOfPacks(ctx, amount, packs)
+		require.NoError(t, err)
+
+		actual, err := NumberOfPacks(ctx, amount, packs, WithCache(cache))
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual, "amount %d", amount)
+	}
+
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestNumberOfPacks_WithCacheConcurrently(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		cache = NewTableCache(1 << 20)
+		packs = []int64{7, 11, 13, 17}
+		wg    sync.WaitGroup
+	)
+
+	for amount := int64(1); amount <= 64; amount++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			expected, found := bruteForce(amount*5, packs, constraints{})
+			assert.True(t, found)
+
+			actual, err := NumberOfPacks(ctx, amount*5, packs, WithCache(cache))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, scoreOf(amount*5, actual, nil), "amount %d: %v", amount*5, actual)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkNumberOfPacks_Cached(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		cache = NewTableCache(1 << 20)
+		packs = []int64{23, 31, 53}
+	)
+
+	for b.Loop() {
+		NumberOfPacks(ctx, 1_000_000, packs, WithCache(cache))
+	}
+}
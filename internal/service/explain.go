@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// Candidate is a combination of packs considered by a calculation along with its measures.
+type Candidate struct {
+	Packs     map[int64]int64 // Number of packs by size
+	Shipped   int64           // Total items shipped
+	Overshoot int64           // Items shipped above the amount, negative if fewer are shipped
+	Count     int64           // Number of packs
+	Cost      int64           // Total cost of packs
+}
+
+// Explanation describes why a combination was chosen: its measures and the runner-up
+// combinations ranked next by the strategy, the best combination of every other quantity.
+type Explanation struct {
+	Strategy     string      // Name of the strategy ranking combinations
+	Chosen       Candidate   // Optimal combination
+	Alternatives []Candidate // Runner-up combinations from the best one
+}
+
+// ExplainNumberOfPacks calculates the optimal combination of packs like NumberOfPacks
+// and explains it along with up to alternatives runner-up combinations.
+func ExplainNumberOfPacks(
+	ctx context.Context,
+	amount int64,
+	packs []int64,
+	alternatives int,
+	opts ...Option,
+) (*Explanation, error) {
+	if alternatives < 0 {
+		return nil, fmt.Errorf("number of alternatives must not be negative: %d", alternatives)
+	}
+
+	var options = newOptions(opts)
+
+	combinations, err := rankCombinations(ctx, amount, packs, alternatives+1, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var explanation = Explanation{
+		Strategy:     options.strategy.Name(),
+		Chosen:       newCandidate(amount, combinations[0], options.costs),
+		Alternatives: make([]Candidate, 0, len(combinations)-1),
+	}
+
+	for _, combination := range combinations[1:] {
+		explanation.Alternatives = append(explanation.Alternatives, newCandidate(amount, combination, options.costs))
+	}
+
+	return &explanation, nil
+}
+
+// newCandidate measures the combination shipped for the amount.
+func newCandidate(amount int64, combination map[int64]int64, costs map[int64]int64) Candidate {
+	var candidate = Candidate{Packs: combination}
+
+	for pack, count := range combination {
+		candidate.Shipped += pack * count
+		candidate.Count += count
+		candidate.Cost += costs[pack] * count
+	}
+
+	candidate.Overshoot = candidate.Shipped - amount
+
+	return candidate
+}
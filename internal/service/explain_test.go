@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainNumberOfPacks(t *testing.T) {
+	ctx := context.Background()
+	packs := []int64{250, 500, 1000}
+
+	t.Run("least overshoot", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 1001, packs, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, &Explanation{
+			Strategy: "least_overshoot",
+			Chosen:   Candidate{Packs: map[int64]int64{250: 1, 1000: 1}, Shipped: 1250, Overshoot: 249, Count: 2},
+			Alternatives: []Candidate{
+				{Packs: map[int64]int64{500: 1, 1000: 1}, Shipped: 1500, Overshoot: 499, Count: 2},
+				{Packs: map[int64]int64{250: 1, 500: 1, 1000: 1}, Shipped: 1750, Overshoot: 749, Count: 3},
+			},
+		}, explanation)
+	})
+
+	t.Run("fewest packs", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 1001, packs, 10, WithStrategy(FewestPacks()))
+
+		require.NoError(t, err)
+
+		var shipped []int64
+		for _, alternative := range explanation.Alternatives {
+			shipped = append(shipped, alternative.Shipped)
+		}
+
+		assert.Equal(t, int64(1250), explanation.Chosen.Shipped)
+		assert.Equal(t, []int64{1500, 2000, 1750, 2250}, shipped)
+	})
+
+	t.Run("costs", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 1000, packs, 1,
+			WithStrategy(Cheapest()),
+			WithCosts(map[int64]int64{250: 1, 500: 3, 1000: 5}),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, Candidate{Packs: map[int64]int64{250: 4}, Shipped: 1000, Count: 4, Cost: 4}, explanation.Chosen)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{250: 5}, Shipped: 1250, Overshoot: 250, Count: 5, Cost: 5},
+		}, explanation.Alternatives)
+	})
+
+	t.Run("undershoot", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 1001, packs, 0, WithTolerance(Tolerance{Items: 1}, Tolerance{}))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(-1), explanation.Chosen.Overshoot)
+		assert.Empty(t, explanation.Alternatives)
+	})
+
+	t.Run("zero amount", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 0, packs, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, Candidate{Packs: map[int64]int64{}}, explanation.Chosen)
+		assert.Empty(t, explanation.Alternatives)
+	})
+
+	t.Run("negative alternatives", func(t *testing.T) {
+		explanation, err := ExplainNumberOfPacks(ctx, 1001, packs, -1)
+
+		assert.Error(t, err)
+		assert.Nil(t, explanation)
+	})
+}
+
+func TestExplainNumberOfPacks_ChoosesLikeNumberOfPacks(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = rand.New(rand.NewSource(5))
+		packs  = []int64{4, 6, 9}
+	)
+
+	for range 200 {
+		var (
+			amount = random.Int63n(100) + 1
+			opts   []Option
+		)
+
+		if random.Intn(2) == 0 {
+			opts = append(opts, WithStock(map[int64]int64{9: random.Int63n(5)}))
+		}
+
+		expected, err := NumberOfPacks(ctx, amount, packs, opts...)
+		require.NoError(t, err)
+
+		explanation, err := ExplainNumberOfPacks(ctx, amount, packs, 3, opts...)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, explanation.Chosen.Packs, "amount %d", amount)
+
+		var previous = explanation.Chosen
+		for _, alternative := range explanation.Alternatives {
+			assert.NotEqual(t, previous.Shipped, alternative.Shipped, "amount %d", amount)
+			assert.LessOrEqual(t, previous.Overshoot, alternative.Overshoot, "amount %d", amount)
+
+			previous = alternative
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/store"
+)
+
+//go:generate mockgen -source=hierarchy.go -destination=mocks/hierarchy.go -typed
+
+// ErrInvalidHierarchy is returned when a packaging hierarchy can't be created from its levels.
+var ErrInvalidHierarchy = errors.New("invalid hierarchy")
+
+// Level is a level of a packaging hierarchy for calculation.
+type Level struct {
+	Name  string          // Name of containers of the level
+	Packs []int64         // Capacities of containers in units of the level below
+	Costs map[int64]int64 // Cost of a single container by capacity
+	Rules map[int64]Rule  // Quantity rules of containers by capacity
+}
+
+// LevelPlan is the packing of a single hierarchy level.
+type LevelPlan struct {
+	Name       string          // Name of containers of the level
+	Units      int64           // Units of the level below packed, items for the first level
+	Containers map[int64]int64 // Number of containers by capacity
+	Count      int64           // Total number of containers
+}
+
+// PackHierarchy calculates the packing plan of the amount level by level from the innermost one:
+// containers of every level are calculated by NumberOfPacks for the number of containers
+// of the level below following the level's costs and quantity rules. Options apply to every level,
+// but tolerances only to the first one, so that every upper level holds all containers of the level below.
+func PackHierarchy(ctx context.Context, amount int64, levels []Level, opts ...Option) ([]LevelPlan, error) {
+	var (
+		plans = make([]LevelPlan, 0, len(levels))
+		units = amount
+	)
+
+	for i, level := range levels {
+		var options = append(slices.Clip(opts), WithCosts(level.Costs), WithRules(level.Rules))
+		if i > 0 {
+			options = append(options, WithTolerance(Tolerance{}, Tolerance{}))
+		}
+
+		containers, err := NumberOfPacks(ctx, units, level.Packs, options...)
+		if err != nil {
+			return nil, fmt.Errorf("level %q: %w", level.Name, err)
+		}
+
+		var plan = LevelPlan{Name: level.Name, Units: units, Containers: containers}
+		for _, count := range containers {
+			plan.Count += count
+		}
+
+		plans = append(plans, plan)
+		units = plan.Count
+	}
+
+	return plans, nil
+}
+
+// HierarchyService defines the interface for packaging hierarchy operations.
+type HierarchyService interface {
+	// CreateHierarchy creates a packaging hierarchy from levels ordered from the innermost one
+	CreateHierarchy(ctx context.Context, name string, levels ...model.HierarchyLevel) (string, error)
+	// GetHierarchyByID retrieves a packaging hierarchy by its unique ID
+	GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error)
+	// PlanHierarchy calculates the packing plan of the amount with the hierarchy
+	PlanHierarchy(ctx context.Context, id string, amount int64, opts ...Option) ([]LevelPlan, error)
+}
+
+// hierarchyService implements the HierarchyService interface.
+type hierarchyService struct {
+	store store.Store // Database store for hierarchy and pack persistence
+}
+
+// NewHierarchyService creates a new hierarchy service instance with the given store.
+func NewHierarchyService(store store.Store) HierarchyService {
+	return &hierarchyService{
+		store: store,
+	}
+}
+
+// CreateHierarchy creates a packaging hierarchy from the provided levels.
+// Only names and pack configuration hashes of levels are used, identifiers and positions
+// are generated. Every level must reference an existing pack configuration.
+func (s *hierarchyService) CreateHierarchy(
+	ctx context.Context,
+	name string,
+	levels ...model.HierarchyLevel,
+) (string, error) {
+	if len(levels) == 0 {
+		return "", fmt.Errorf("%w: levels can't be empty", ErrInvalidHierarchy)
+	}
+
+	var hierarchy = model.Hierarchy{
+		ID:     uuid.NewString(),
+		Name:   name,
+		Levels: make([]model.HierarchyLevel, len(levels)),
+	}
+
+	for i, level := range levels {
+		if level.Name == "" || level.PacksHash == "" {
+			return "", fmt.Errorf("%w: level %d must have a name and a packs hash", ErrInvalidHierarchy, i)
+		}
+
+		// Check that the referenced pack configuration exists
+		_, err := s.store.GetPackByHash(ctx, level.PacksHash)
+		switch {
+		case err == nil:
+		case errors.Is(err, store.ErrNotFound):
+			return "", fmt.Errorf("%w: level %q: packs not found by hash: %s", ErrInvalidHierarchy, level.Name, level.PacksHash)
+		default:
+			return "", err
+		}
+
+		hierarchy.Levels[i] = model.HierarchyLevel{
+			ID:          uuid.NewString(),
+			HierarchyID: hierarchy.ID,
+			Position:    i,
+			Name:        level.Name,
+			PacksHash:   level.PacksHash,
+		}
+	}
+
+	// Persist hierarchy to database
+	if err := s.store.SaveHierarchy(ctx, &hierarchy); err != nil {
+		return "", err
+	}
+
+	return hierarchy.ID, nil
+}
+
+// GetHierarchyByID retrieves a packaging hierarchy by its unique identifier.
+func (s *hierarchyService) GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error) {
+	return s.store.GetHierarchyByID(ctx, id)
+}
+
+// PlanHierarchy resolves pack configurations of the hierarchy levels and calculates
+// the packing plan of the amount with them.
+func (s *hierarchyService) PlanHierarchy(
+	ctx context.Context,
+	id string,
+	amount int64,
+	opts ...Option,
+) ([]LevelPlan, error) {
+	hierarchy, err := s.store.GetHierarchyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var levels = make([]Level, len(hierarchy.Levels))
+	for i, level := range hierarchy.Levels {
+		pack, err := s.store.GetPackByHash(ctx, level.PacksHash)
+		if err != nil {
+			return nil, fmt.Errorf("level %q: %w", level.Name, err)
+		}
+
+		levels[i] = Level{
+			Name:  level.Name,
+			Packs: pack.GetPacks(),
+			Costs: pack.GetCosts(),
+			Rules: PackRules(pack),
+		}
+	}
+
+	return PackHierarchy(ctx, amount, levels, opts...)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/kliuchnikovv/packulator/internal/store"
+	mock_store "github.com/kliuchnikovv/packulator/internal/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPackHierarchy(t *testing.T) {
+	ctx := context.Background()
+	levels := []Level{
+		{Name: "pack", Packs: []int64{250, 500, 1000}},
+		{Name: "carton", Packs: []int64{4, 10}},
+		{Name: "pallet", Packs: []int64{50}},
+	}
+
+	t.Run("nested packing", func(t *testing.T) {
+		plans, err := PackHierarchy(ctx, 12001, levels)
+
+		require.NoError(t, err)
+		assert.Equal(t, []LevelPlan{
+			{Name: "pack", Units: 12001, Containers: map[int64]int64{250: 1, 1000: 12}, Count: 13},
+			{Name: "carton", Units: 13, Containers: map[int64]int64{4: 1, 10: 1}, Count: 2},
+			{Name: "pallet", Units: 2, Containers: map[int64]int64{50: 1}, Count: 1},
+		}, plans)
+	})
+
+	t.Run("tolerance applies to first level only", func(t *testing.T) {
+		plans, err := PackHierarchy(ctx, 1001, levels[:2], WithTolerance(Tolerance{Items: 1}, Tolerance{}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1000: 1}, plans[0].Containers)
+		assert.Equal(t, map[int64]int64{4: 1}, plans[1].Containers)
+	})
+
+	t.Run("level costs", func(t *testing.T) {
+		plans, err := PackHierarchy(ctx, 1000, []Level{
+			{Name: "pack", Packs: []int64{250, 500, 1000}, Costs: map[int64]int64{250: 1, 500: 3, 1000: 5}},
+			{Name: "carton", Packs: []int64{4, 10}},
+		}, WithStrategy(Cheapest()))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 4}, plans[0].Containers)
+		assert.Equal(t, map[int64]int64{4: 1}, plans[1].Containers)
+	})
+
+	t.Run("level rules", func(t *testing.T) {
+		// Pallets of 2 cartons are shipped only in full layers of 4
+		plans, err := PackHierarchy(ctx, 50, []Level{
+			{Name: "carton", Packs: []int64{10}},
+			{Name: "pallet", Packs: []int64{2}, Rules: map[int64]Rule{2: {Step: 4}}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{10: 5}, plans[0].Containers)
+		assert.Equal(t, map[int64]int64{2: 4}, plans[1].Containers)
+	})
+
+	t.Run("level error", func(t *testing.T) {
+		plans, err := PackHierarchy(ctx, 2000, []Level{
+			{Name: "pack", Packs: []int64{1000}},
+			{Name: "carton", Packs: []int64{1}},
+		}, WithStrategy(WithinPacks(1)))
+
+		assert.ErrorIs(t, err, ErrNoCombination)
+		assert.ErrorContains(t, err, `level "pack"`)
+		assert.Nil(t, plans)
+	})
+}
+
+func TestHierarchyService_CreateHierarchy(t *testing.T) {
+	levels := []model.HierarchyLevel{
+		{Name: "pack", PacksHash: "packs"},
+		{Name: "carton", PacksHash: "cartons"},
+	}
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(&model.Pack{}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{}, nil)
+
+		var saved *model.Hierarchy
+		mockStore.EXPECT().SaveHierarchy(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, hierarchy *model.Hierarchy) error {
+				saved = hierarchy
+				return nil
+			})
+
+		id, err := service.CreateHierarchy(context.Background(), "pallets", levels...)
+
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Equal(t, saved.ID, id)
+		assert.Equal(t, "pallets", saved.Name)
+		require.Len(t, saved.Levels, 2)
+
+		for i, level := range saved.Levels {
+			assert.NotEmpty(t, level.ID)
+			assert.Equal(t, id, level.HierarchyID)
+			assert.Equal(t, i, level.Position)
+			assert.Equal(t, levels[i].Name, level.Name)
+			assert.Equal(t, levels[i].PacksHash, level.PacksHash)
+		}
+	})
+
+	t.Run("unknown packs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(nil, store.ErrNotFound)
+
+		id, err := service.CreateHierarchy(context.Background(), "pallets", levels...)
+
+		assert.ErrorIs(t, err, ErrInvalidHierarchy)
+		assert.Empty(t, id)
+	})
+
+	t.Run("invalid levels", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		_, err := service.CreateHierarchy(context.Background(), "pallets")
+		assert.ErrorIs(t, err, ErrInvalidHierarchy)
+
+		_, err = service.CreateHierarchy(context.Background(), "pallets", model.HierarchyLevel{Name: "pack"})
+		assert.ErrorIs(t, err, ErrInvalidHierarchy)
+	})
+}
+
+func TestHierarchyService_PlanHierarchy(t *testing.T) {
+	t.Run("successful planning", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(&model.Hierarchy{
+			ID: "hierarchy-1",
+			Levels: []model.HierarchyLevel{
+				{Name: "pack", PacksHash: "packs"},
+				{Name: "carton", PacksHash: "cartons"},
+			},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 250}, {Size: 500}},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 3}},
+		}, nil)
+
+		plans, err := service.PlanHierarchy(context.Background(), "hierarchy-1", 1000)
+
+		require.NoError(t, err)
+		assert.Equal(t, []LevelPlan{
+			{Name: "pack", Units: 1000, Containers: map[int64]int64{500: 2}, Count: 2},
+			{Name: "carton", Units: 2, Containers: map[int64]int64{3: 1}, Count: 1},
+		}, plans)
+	})
+
+	t.Run("stored rules", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(&model.Hierarchy{
+			ID: "hierarchy-1",
+			Levels: []model.HierarchyLevel{
+				{Name: "carton", PacksHash: "cartons"},
+				{Name: "pallet", PacksHash: "pallets"},
+			},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "cartons").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 10}},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "pallets").Return(&model.Pack{
+			PackItems: []model.PackItem{{Size: 2, Step: 4}},
+		}, nil)
+
+		plans, err := service.PlanHierarchy(context.Background(), "hierarchy-1", 50)
+
+		require.NoError(t, err)
+		assert.Equal(t, []LevelPlan{
+			{Name: "carton", Units: 50, Containers: map[int64]int64{10: 5}, Count: 5},
+			{Name: "pallet", Units: 5, Containers: map[int64]int64{2: 4}, Count: 4},
+		}, plans)
+	})
+
+	t.Run("hierarchy not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "nonexistent").Return(nil, store.ErrNotFound)
+
+		plans, err := service.PlanHierarchy(context.Background(), "nonexistent", 1000)
+
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		assert.Nil(t, plans)
+	})
+
+	t.Run("level packs not found", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewHierarchyService(mockStore)
+
+		mockStore.EXPECT().GetHierarchyByID(gomock.Any(), "hierarchy-1").Return(&model.Hierarchy{
+			Levels: []model.HierarchyLevel{{Name: "pack", PacksHash: "packs"}},
+		}, nil)
+		mockStore.EXPECT().GetPackByHash(gomock.Any(), "packs").Return(nil, store.ErrNotFound)
+
+		_, err := service.PlanHierarchy(context.Background(), "hierarchy-1", 1000)
+
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+}
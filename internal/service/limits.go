@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Input errors
+var (
+	ErrAmountTooLarge   = errors.New("amount is too large to calculate") // Returned when the amount or its tables exceed limits
+	ErrInvalidPackSize  = errors.New("pack sizes must be positive")      // Returned when a pack size can't be used
+	ErrTooManyPackSizes = errors.New("too many distinct pack sizes")     // Returned when pack sizes exceed the limit
+)
+
+// Defaults of input limits
+const (
+	defaultMaxAmount = math.MaxInt64 // No limit of the amount
+	defaultMaxMemory = 1 << 30       // Memory limit of tables of a calculation in bytes
+)
+
+// InputLimits guards calculations against inputs they can't handle in reasonable memory.
+// Zero fields take defaults: unlimited amount, 255 pack sizes and 1 GiB of tables.
+type InputLimits struct {
+	MaxAmount    int64 // Largest amount
+	MaxPackSizes int   // Most distinct pack sizes, 255 at most
+	MaxMemory    int64 // Largest estimated memory of tables of a calculation in bytes
+}
+
+// WithInputLimits guards calculations with the limits. Calculations exceeding them
+// fail with ErrAmountTooLarge or ErrTooManyPackSizes before any table is built.
+func WithInputLimits(limits InputLimits) Option {
+	return func(o *options) {
+		o.input = limits
+	}
+}
+
+// withDefaults returns the limits with zero fields set to defaults.
+func (l InputLimits) withDefaults() InputLimits {
+	if l.MaxAmount <= 0 {
+		l.MaxAmount = defaultMaxAmount
+	}
+
+	if l.MaxPackSizes <= 0 || l.MaxPackSizes > maxPackSizes {
+		l.MaxPackSizes = maxPackSizes
+	}
+
+	if l.MaxMemory <= 0 {
+		l.MaxMemory = defaultMaxMemory
+	}
+
+	return l
+}
+
+// checkAmount returns ErrAmountTooLarge if the amount exceeds the limit.
+func (l InputLimits) checkAmount(amount int64) error {
+	if amount > l.MaxAmount {
+		return fmt.Errorf("%w: %d (max %d)", ErrAmountTooLarge, amount, l.MaxAmount)
+	}
+
+	return nil
+}
+
+// checkPackSizes returns ErrTooManyPackSizes if the number of distinct pack sizes exceeds the limit.
+func (l InputLimits) checkPackSizes(sizes int) error {
+	if sizes > l.MaxPackSizes {
+		return fmt.Errorf("%w: %d (max %d)", ErrTooManyPackSizes, sizes, l.MaxPackSizes)
+	}
+
+	return nil
+}
+
+// checkMemory returns ErrAmountTooLarge if a table of all sums up to maxRange
+// taking bytesPerSum each exceeds the memory limit. Negative ranges are overflown ones.
+func (l InputLimits) checkMemory(maxRange, bytesPerSum int64) error {
+	if maxRange < 0 || maxRange >= l.MaxMemory/bytesPerSum {
+		return fmt.Errorf("%w: tables need about %d MiB (max %d MiB)", ErrAmountTooLarge,
+			estimateMiB(maxRange, bytesPerSum), l.MaxMemory>>20)
+	}
+
+	return nil
+}
+
+// estimateMiB returns memory of maxRange+1 sums taking bytesPerSum each in MiB without overflow.
+func estimateMiB(maxRange, bytesPerSum int64) int64 {
+	if maxRange < 0 || maxRange >= math.MaxInt64/bytesPerSum {
+		return math.MaxInt64 >> 20
+	}
+
+	return (maxRange + 1) * bytesPerSum >> 20
+}
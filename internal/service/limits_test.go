@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacks_InputLimits(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		packs = []int64{250, 500, 1000}
+	)
+
+	t.Run("amount too large", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1_000_001, packs, WithInputLimits(InputLimits{MaxAmount: 1_000_000}))
+
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+		assert.Nil(t, result)
+
+		result, err = NumberOfPacks(ctx, 1_000_000, packs, WithInputLimits(InputLimits{MaxAmount: 1_000_000}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1000: 1000}, result)
+	})
+
+	t.Run("too many pack sizes", func(t *testing.T) {
+		_, err := NumberOfPacks(ctx, 1000, packs, WithInputLimits(InputLimits{MaxPackSizes: 2}))
+
+		assert.ErrorIs(t, err, ErrTooManyPackSizes)
+
+		// Duplicates are counted once
+		_, err = NumberOfPacks(ctx, 1000, []int64{250, 250, 500}, WithInputLimits(InputLimits{MaxPackSizes: 2}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("table too large", func(t *testing.T) {
+		var opts = []Option{WithStrategy(Cheapest()), WithInputLimits(InputLimits{MaxMemory: 1 << 20})}
+
+		_, err := NumberOfPacks(ctx, 1_000_000_000_000_000, []int64{23, 31, 53}, opts...)
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+
+		_, err = NumberOfPacks(ctx, 10_000, []int64{23, 31, 53}, opts...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("counted table too large", func(t *testing.T) {
+		var opts = []Option{WithStrategy(Cheapest()), WithCosts(map[int64]int64{1: 1, 100: 200}), WithMaxPacks(99)}
+
+		_, err := NumberOfPacks(ctx, 9_000, []int64{1, 100}, append(opts, WithInputLimits(InputLimits{MaxMemory: 1 << 20}))...)
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+
+		_, err = NumberOfPacks(ctx, 9_000, []int64{1, 100}, opts...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("bounded table too large", func(t *testing.T) {
+		var opts = []Option{WithStock(map[int64]int64{23: 1}), WithInputLimits(InputLimits{MaxMemory: 1 << 20})}
+
+		_, err := NumberOfPacks(ctx, 1_000_000_000_000_000, []int64{23, 31, 53}, opts...)
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+	})
+
+	t.Run("range beyond int64", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 5, []int64{math.MaxInt64, math.MaxInt64 - 1})
+
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+		assert.Nil(t, result)
+
+		assert.ErrorIs(t, InputLimits{}.withDefaults().checkMemory(-1, 8), ErrAmountTooLarge)
+	})
+
+	t.Run("huge amount filled analytically", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1_000_000_000_000_000, packs, WithInputLimits(InputLimits{MaxMemory: 1 << 20}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1000: 1_000_000_000_000}, result)
+	})
+}
+
+func TestInputLimits_WithDefaults(t *testing.T) {
+	assert.Equal(t,
+		InputLimits{MaxAmount: defaultMaxAmount, MaxPackSizes: maxPackSizes, MaxMemory: defaultMaxMemory},
+		InputLimits{}.withDefaults(),
+	)
+	assert.Equal(t,
+		InputLimits{MaxAmount: 10, MaxPackSizes: maxPackSizes, MaxMemory: 1 << 20},
+		InputLimits{MaxAmount: 10, MaxPackSizes: 1000, MaxMemory: 1 << 20}.withDefaults(),
+	)
+}
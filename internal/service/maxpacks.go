@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrInfeasible is returned when no combination covering the amount fits the maximum number of packs.
+var ErrInfeasible = errors.New("no combination fits the maximum number of packs")
+
+// maxCountedEntries bounds the number of entries of a table kept for every number of packs.
+const maxCountedEntries = 1 << 24
+
+// InfeasibleError reports that the amount can't be covered with the maximum number of packs.
+type InfeasibleError struct {
+	MaxPacks int64 // Largest number of packs allowed
+	MinPacks int64 // Fewest packs covering the amount, zero if unknown
+}
+
+// Error describes the infeasible constraint.
+func (e *InfeasibleError) Error() string {
+	if e.MinPacks == 0 {
+		return fmt.Sprintf("%s: more than %d packs are needed", ErrInfeasible, e.MaxPacks)
+	}
+
+	return fmt.Sprintf("%s: %d packs are needed, max %d", ErrInfeasible, e.MinPacks, e.MaxPacks)
+}
+
+// Unwrap makes the error match ErrInfeasible.
+func (e *InfeasibleError) Unwrap() error {
+	return ErrInfeasible
+}
+
+// WithMaxPacks limits the total number of packs of a combination, zero means no limit.
+// If no combination fits, the calculation fails with InfeasibleError. Cost strategies
+// can't combine it with stock or rules restricting combinations within the limit and fail
+// with ErrUnsupportedOptions, looser ones are ignored.
+func WithMaxPacks(limit int64) Option {
+	return func(o *options) {
+		o.maxPacks = limit
+	}
+}
+
+// limitedSolution hides combinations of more than limit packs, bulk packs included,
+// and records the fewest packs of all combinations it was asked about.
+type limitedSolution struct {
+	solution
+
+	limit   int64  // Largest number of packs of a visible combination
+	bulk    int64  // Packs taken analytically on top of every combination
+	fewest  *int64 // Fewest packs of checked combinations, zero if none was checked
+	visible *bool  // Whether any combination fits the limit
+}
+
+// variant returns the best way to reach the sum if it fits the limit.
+func (s limitedSolution) variant(w window, sum int64) *variant {
+	var current = s.solution.variant(w, sum)
+	if current == nil {
+		return nil
+	}
+
+	var packs = current.Packs + s.bulk
+	if *s.fewest == 0 || packs < *s.fewest {
+		*s.fewest = packs
+	}
+
+	if packs > s.limit {
+		return nil
+	}
+
+	*s.visible = true
+
+	return current
+}
+
+// dropLooseLimits removes stock and rules which can't restrict combinations of at most limit packs,
+// so they don't need a bounded table.
+func dropLooseLimits(stock map[int64]int64, rules map[int64]Rule, limit int64) {
+	maps.DeleteFunc(stock, func(_, available int64) bool {
+		return available >= limit
+	})
+
+	maps.DeleteFunc(rules, func(_ int64, rule Rule) bool {
+		return rule.Min <= 1 && rule.Step <= 1 && (rule.Max == 0 || rule.Max >= limit)
+	})
+}
+
+// limitPacks wraps the selector to pick only combinations of at most limit packs.
+// If none fits, calculation fails with InfeasibleError instead of ErrNoCombination.
+func limitPacks(selector selectVariants, limit int64) (selectVariants, func(error) error) {
+	var (
+		fewest  int64
+		visible bool
+	)
+
+	var limited = func(w window, maxRange, bulk int64, solution solution) []*variant {
+		return selector(w, maxRange, bulk, limitedSolution{
+			solution: solution,
+			limit:    limit,
+			bulk:     bulk,
+			fewest:   &fewest,
+			visible:  &visible,
+		})
+	}
+
+	var infeasible = func(err error) error {
+		if !errors.Is(err, ErrNoCombination) || visible {
+			return err
+		}
+
+		return &InfeasibleError{MaxPacks: limit, MinPacks: fewest}
+	}
+
+	return limited, infeasible
+}
+
+// countedTable holds the dynamic programming state of a calculation limiting the number
+// of packs when combinations of a sum are compared by cost first: the cheapest combination
+// may use too many packs while a costlier one fits, so the cheapest combination
+// of every number of packs up to the limit is kept for every sum.
+type countedTable struct {
+	tableConfig
+
+	costs [][]int64 // Cost of the cheapest combination of the number of packs adding up to the sum
+	last  [][]uint8 // 1-based index of the last pack of the combination, 0 if unreachable
+}
+
+// countedLimit returns the most packs a counted table keeps for sums up to maxRange.
+// Combinations of such sums can't have more packs than of the smallest size.
+func (c tableConfig) countedLimit(maxRange int64) int64 {
+	return min(c.maxPacks, maxRange/c.packs[0])
+}
+
+// countedBytesPerSum returns memory a counted table takes for every sum up to maxRange:
+// the cost and the last pack for every number of packs.
+func (c tableConfig) countedBytesPerSum(maxRange int64) int64 {
+	return saturatedMul(saturatedAdd(c.countedLimit(maxRange), 1), 8+1)
+}
+
+// newCountedTable builds the table for all sums from zero up to maxRange
+// and numbers of packs up to the limit of the config.
+func newCountedTable(config tableConfig, maxRange int64, stop *checkpoint) (*countedTable, error) {
+	var limit = config.countedLimit(maxRange)
+	if limit+1 > maxCountedEntries/(maxRange+1) {
+		return nil, fmt.Errorf("%w: maximum number of %d packs is too large for the amount with cost strategies",
+			ErrAmountTooLarge, config.maxPacks)
+	}
+
+	var t = &countedTable{
+		tableConfig: config,
+		costs:       make([][]int64, limit+1),
+		last:        make([][]uint8, limit+1),
+	}
+
+	for k := range t.costs {
+		t.costs[k] = make([]int64, maxRange+1)
+		t.last[k] = make([]uint8, maxRange+1)
+	}
+
+	for k := 1; k < len(t.costs); k++ {
+		for sum := int64(1); sum <= maxRange; sum++ {
+			if err := stop.step(); err != nil {
+				return nil, err
+			}
+
+			for i := len(t.packs) - 1; i >= 0; i-- {
+				var prev = sum - t.packs[i]
+				if prev < 0 || !t.reachable(k-1, prev) {
+					continue
+				}
+
+				var cost = t.costs[k-1][prev] + t.unitCosts[i]
+				if t.last[k][sum] == 0 || cost < t.costs[k][sum] {
+					t.costs[k][sum] = cost
+					t.last[k][sum] = uint8(i + 1)
+				}
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// reachable reports whether the sum can be composed of exactly count packs.
+func (t *countedTable) reachable(count int, sum int64) bool {
+	return (count == 0 && sum == 0) || t.last[count][sum] != 0
+}
+
+// best returns the number of packs of the best combination for the sum by criteria, -1 if unreachable.
+func (t *countedTable) best(sum int64) int {
+	var (
+		best     = -1
+		bestCost int64
+	)
+
+	for count := range t.costs {
+		if !t.reachable(count, sum) {
+			continue
+		}
+
+		if best < 0 || isBetterSum(t.criteria, uint32(count), uint32(best), t.costs[count][sum], bestCost) {
+			best, bestCost = count, t.costs[count][sum]
+		}
+	}
+
+	return best
+}
+
+// variant returns the best way to reach the sum or nil if it's unreachable.
+func (t *countedTable) variant(w window, sum int64) *variant {
+	var count = t.best(sum)
+	if count < 0 {
+		return nil
+	}
+
+	return &variant{
+		sum: sum,
+		Score: Score{
+			Overshoot: w.overshoot(sum),
+			Packs:     int64(count),
+			Cost:      t.costs[count][sum],
+		},
+	}
+}
+
+// combination rebuilds the packs of the best combination for the sum.
+func (t *countedTable) combination(sum int64) map[int64]int64 {
+	var result = make(map[int64]int64)
+
+	for count := t.best(sum); count > 0; count-- {
+		var pack = t.packs[t.last[count][sum]-1]
+
+		result[pack]++
+		sum -= pack
+	}
+
+	return result
+}
+
+// limitedCriteria returns criteria of a table for a calculation limiting the number of packs
+// and whether the table must keep combinations of every number of packs. Combinations
+// with fewer packs are preferred among equal ones, so the fewest packs of every sum are known,
+// unless cost goes first.
+func limitedCriteria(criteria []Criterion) ([]Criterion, bool) {
+	for _, criterion := range criteria {
+		switch criterion {
+		case CriterionPacks:
+			return criteria, false
+		case CriterionCost:
+			return criteria, true
+		}
+	}
+
+	return append(slices.Clone(criteria), CriterionPacks), false
+}
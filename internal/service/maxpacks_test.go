@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacks_WithMaxPacks(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		packs = []int64{250, 500, 1000}
+	)
+
+	t.Run("least overshoot within limit", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 501, packs, WithMaxPacks(1))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1000: 1}, result)
+	})
+
+	t.Run("limit not reached", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 501, packs, WithMaxPacks(2))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 1, 500: 1}, result)
+	})
+
+	t.Run("infeasible", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1001, packs, WithMaxPacks(1))
+
+		assert.ErrorIs(t, err, ErrInfeasible)
+		assert.Nil(t, result)
+
+		var infeasible *InfeasibleError
+		require.ErrorAs(t, err, &infeasible)
+		assert.Equal(t, InfeasibleError{MaxPacks: 1, MinPacks: 2}, *infeasible)
+	})
+
+	t.Run("huge amount", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1_000_000_000_001, packs, WithMaxPacks(1_000_000_001))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 1, 1000: 1_000_000_000}, result)
+
+		_, err = NumberOfPacks(ctx, 1_000_000_000_001, packs, WithMaxPacks(1_000_000_000))
+
+		var infeasible *InfeasibleError
+		require.ErrorAs(t, err, &infeasible)
+		assert.Equal(t, int64(1_000_000_001), infeasible.MinPacks)
+	})
+
+	t.Run("rejected by strategy", func(t *testing.T) {
+		_, err := NumberOfPacks(ctx, 1001, packs, WithMaxPacks(3), WithStrategy(WithinPacks(1)))
+
+		assert.ErrorIs(t, err, ErrNoCombination)
+		assert.NotErrorIs(t, err, ErrInfeasible)
+	})
+
+	t.Run("cost first", func(t *testing.T) {
+		var opts = []Option{
+			WithStrategy(Cheapest()),
+			WithCosts(map[int64]int64{1: 1, 10: 20}),
+		}
+
+		result, err := NumberOfPacks(ctx, 25, []int64{1, 10}, opts...)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1: 25}, result)
+
+		// The cheapest combination of every sum uses too many packs
+		result, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(5))...)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{10: 3}, result)
+
+		result, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(8))...)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1: 5, 10: 2}, result)
+
+		_, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(2))...)
+		assert.ErrorIs(t, err, ErrInfeasible)
+
+		_, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(5), WithStock(map[int64]int64{10: 2}))...)
+		assert.ErrorIs(t, err, ErrUnsupportedOptions)
+
+		// Limits no combination within max packs reaches don't restrict it
+		result, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(5),
+			WithStock(map[int64]int64{1: 100}), WithRules(map[int64]Rule{10: {Min: 1, Max: 40}}))...)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{10: 3}, result)
+
+		_, err = NumberOfPacks(ctx, 25, []int64{1, 10}, append(opts, WithMaxPacks(5), WithRules(map[int64]Rule{10: {Max: 4}}))...)
+		assert.ErrorIs(t, err, ErrUnsupportedOptions)
+	})
+
+	t.Run("stock", func(t *testing.T) {
+		var stock = map[int64]int64{250: 10, 500: 2, 1000: 0}
+
+		result, err := NumberOfPacks(ctx, 900, packs, WithMaxPacks(2), WithStock(stock))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result[500])
+		assert.Zero(t, result[250]+result[1000])
+
+		_, err = NumberOfPacks(ctx, 1001, packs, WithMaxPacks(2), WithStock(stock))
+		assert.ErrorIs(t, err, ErrInfeasible)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		_, err := NumberOfPacks(ctx, 1001, packs, WithMaxPacks(-1))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNumberOfPacks_WithMaxPacksMatchesReference(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		packs = []int64{4, 6, 9}
+		costs = map[int64]int64{4: 5, 6: 1, 9: 3}
+	)
+
+	for amount := int64(1); amount <= 60; amount++ {
+		for limit := int64(1); limit <= 8; limit++ {
+			// Cheapest combination of at most limit packs covering the amount by brute force
+			var (
+				best     map[int64]int64
+				bestCost int64 = -1
+			)
+
+			for a := int64(0); a <= limit; a++ {
+				for b := int64(0); a+b <= limit; b++ {
+					for c := int64(0); a+b+c <= limit; c++ {
+						var (
+							sum  = 4*a + 6*b + 9*c
+							cost = 5*a + b + 3*c
+						)
+
+						if sum < amount {
+							continue
+						}
+
+						if bestCost < 0 || cost < bestCost {
+							best, bestCost = map[int64]int64{4: a, 6: b, 9: c}, cost
+						}
+					}
+				}
+			}
+
+			result, err := NumberOfPacks(ctx, amount, packs, WithStrategy(Cheapest()), WithCosts(costs), WithMaxPacks(limit))
+			if bestCost < 0 {
+				assert.ErrorIs(t, err, ErrInfeasible, "amount %d, limit %d", amount, limit)
+				continue
+			}
+
+			require.NoError(t, err, "amount %d, limit %d", amount, limit)
+
+			var cost int64
+			for pack, count := range result {
+				cost += costs[pack] * count
+			}
+
+			assert.Equal(t, bestCost, cost, "amount %d, limit %d: %v vs %v", amount, limit, result, best)
+		}
+	}
+}
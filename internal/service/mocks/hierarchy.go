@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: hierarchy.go
+//
+// Generated by this command:
+//
+//	mockgen -source=hierarchy.go -destination=mocks/hierarchy.go -typed
+//
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/kliuchnikovv/packulator/internal/model"
+	service "github.com/kliuchnikovv/packulator/internal/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHierarchyService is a mock of HierarchyService interface.
+type MockHierarchyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHierarchyServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockHierarchyServiceMockRecorder is the mock recorder for MockHierarchyService.
+type MockHierarchyServiceMockRecorder struct {
+	mock *MockHierarchyService
+}
+
+// NewMockHierarchyService creates a new mock instance.
+func NewMockHierarchyService(ctrl *gomock.Controller) *MockHierarchyService {
+	mock := &MockHierarchyService{ctrl: ctrl}
+	mock.recorder = &MockHierarchyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHierarchyService) EXPECT() *MockHierarchyServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateHierarchy mocks base method.
+func (m *MockHierarchyService) CreateHierarchy(ctx context.Context, name string, levels ...model.HierarchyLevel) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name}
+	for _, a := range levels {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateHierarchy", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHierarchy indicates an expected call of CreateHierarchy.
+func (mr *MockHierarchyServiceMockRecorder) CreateHierarchy(ctx, name any, levels ...any) *MockHierarchyServiceCreateHierarchyCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name}, levels...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHierarchy", reflect.TypeOf((*MockHierarchyService)(nil).CreateHierarchy), varargs...)
+	return &MockHierarchyServiceCreateHierarchyCall{Call: call}
+}
+
+// MockHierarchyServiceCreateHierarchyCall wrap *gomock.Call
+type MockHierarchyServiceCreateHierarchyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockHierarchyServiceCreateHierarchyCall) Return(arg0 string, arg1 error) *MockHierarchyServiceCreateHierarchyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockHierarchyServiceCreateHierarchyCall) Do(f func(context.Context, string, ...model.HierarchyLevel) (string, error)) *MockHierarchyServiceCreateHierarchyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockHierarchyServiceCreateHierarchyCall) DoAndReturn(f func(context.Context, string, ...model.HierarchyLevel) (string, error)) *MockHierarchyServiceCreateHierarchyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetHierarchyByID mocks base method.
+func (m *MockHierarchyService) GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHierarchyByID", ctx, id)
+	ret0, _ := ret[0].(*model.Hierarchy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHierarchyByID indicates an expected call of GetHierarchyByID.
+func (mr *MockHierarchyServiceMockRecorder) GetHierarchyByID(ctx, id any) *MockHierarchyServiceGetHierarchyByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHierarchyByID", reflect.TypeOf((*MockHierarchyService)(nil).GetHierarchyByID), ctx, id)
+	return &MockHierarchyServiceGetHierarchyByIDCall{Call: call}
+}
+
+// MockHierarchyServiceGetHierarchyByIDCall wrap *gomock.Call
+type MockHierarchyServiceGetHierarchyByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockHierarchyServiceGetHierarchyByIDCall) Return(arg0 *model.Hierarchy, arg1 error) *MockHierarchyServiceGetHierarchyByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockHierarchyServiceGetHierarchyByIDCall) Do(f func(context.Context, string) (*model.Hierarchy, error)) *MockHierarchyServiceGetHierarchyByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockHierarchyServiceGetHierarchyByIDCall) DoAndReturn(f func(context.Context, string) (*model.Hierarchy, error)) *MockHierarchyServiceGetHierarchyByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PlanHierarchy mocks base method.
+func (m *MockHierarchyService) PlanHierarchy(ctx context.Context, id string, amount int64, opts ...service.Option) ([]service.LevelPlan, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PlanHierarchy", varargs...)
+	ret0, _ := ret[0].([]service.LevelPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanHierarchy indicates an expected call of PlanHierarchy.
+func (mr *MockHierarchyServiceMockRecorder) PlanHierarchy(ctx, id, amount any, opts ...any) *MockHierarchyServicePlanHierarchyCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id, amount}, opts...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanHierarchy", reflect.TypeOf((*MockHierarchyService)(nil).PlanHierarchy), varargs...)
+	return &MockHierarchyServicePlanHierarchyCall{Call: call}
+}
+
+// MockHierarchyServicePlanHierarchyCall wrap *gomock.Call
+type MockHierarchyServicePlanHierarchyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockHierarchyServicePlanHierarchyCall) Return(arg0 []service.LevelPlan, arg1 error) *MockHierarchyServicePlanHierarchyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockHierarchyServicePlanHierarchyCall) Do(f func(context.Context, string, int64, ...service.Option) ([]service.LevelPlan, error)) *MockHierarchyServicePlanHierarchyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockHierarchyServicePlanHierarchyCall) DoAndReturn(f func(context.Context, string, int64, ...service.Option) ([]service.LevelPlan, error)) *MockHierarchyServicePlanHierarchyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -12,8 +12,10 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/kliuchnikovv/packulator/internal/model"
+	store "github.com/kliuchnikovv/packulator/internal/store"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,8 +43,53 @@ func (m *MockPackService) EXPECT() *MockPackServiceMockRecorder {
 	return m.recorder
 }
 
+// CreatePackItems mocks base method.
+func (m *MockPackService) CreatePackItems(ctx context.Context, items ...model.PackItem) (string, bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range items {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreatePackItems", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreatePackItems indicates an expected call of CreatePackItems.
+func (mr *MockPackServiceMockRecorder) CreatePackItems(ctx any, items ...any) *MockPackServiceCreatePackItemsCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, items...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePackItems", reflect.TypeOf((*MockPackService)(nil).CreatePackItems), varargs...)
+	return &MockPackServiceCreatePackItemsCall{Call: call}
+}
+
+// MockPackServiceCreatePackItemsCall wrap *gomock.Call
+type MockPackServiceCreatePackItemsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackServiceCreatePackItemsCall) Return(arg0 string, arg1 bool, arg2 error) *MockPackServiceCreatePackItemsCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackServiceCreatePackItemsCall) Do(f func(context.Context, ...model.PackItem) (string, bool, error)) *MockPackServiceCreatePackItemsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackServiceCreatePackItemsCall) DoAndReturn(f func(context.Context, ...model.PackItem) (string, bool, error)) *MockPackServiceCreatePackItemsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CreatePacks mocks base method.
-func (m *MockPackService) CreatePacks(ctx context.Context, packs ...int64) (string, error) {
+func (m *MockPackService) CreatePacks(ctx context.Context, packs ...int64) (string, bool, error) {
 	m.ctrl.T.Helper()
 	varargs := []any{ctx}
 	for _, a := range packs {
@@ -50,8 +97,9 @@ func (m *MockPackService) CreatePacks(ctx context.Context, packs ...int64) (stri
 	}
 	ret := m.ctrl.Call(m, "CreatePacks", varargs...)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CreatePacks indicates an expected call of CreatePacks.
@@ -68,19 +116,19 @@ type MockPackServiceCreatePacksCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockPackServiceCreatePacksCall) Return(arg0 string, arg1 error) *MockPackServiceCreatePacksCall {
-	c.Call = c.Call.Return(arg0, arg1)
+func (c *MockPackServiceCreatePacksCall) Return(arg0 string, arg1 bool, arg2 error) *MockPackServiceCreatePacksCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockPackServiceCreatePacksCall) Do(f func(context.Context, ...int64) (string, error)) *MockPackServiceCreatePacksCall {
+func (c *MockPackServiceCreatePacksCall) Do(f func(context.Context, ...int64) (string, bool, error)) *MockPackServiceCreatePacksCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockPackServiceCreatePacksCall) DoAndReturn(f func(context.Context, ...int64) (string, error)) *MockPackServiceCreatePacksCall {
+func (c *MockPackServiceCreatePacksCall) DoAndReturn(f func(context.Context, ...int64) (string, bool, error)) *MockPackServiceCreatePacksCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -201,19 +249,58 @@ func (c *MockPackServiceGetPackByIDCall) DoAndReturn(f func(context.Context, str
 	return c
 }
 
-// ListPacks mocks base method.
-func (m *MockPackService) ListPacks(ctx context.Context) ([]model.Pack, error) {
+// ListDeletedPacks mocks base method.
+func (m *MockPackService) ListDeletedPacks(ctx context.Context) ([]model.Pack, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPacks", ctx)
+	ret := m.ctrl.Call(m, "ListDeletedPacks", ctx)
 	ret0, _ := ret[0].([]model.Pack)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ListDeletedPacks indicates an expected call of ListDeletedPacks.
+func (mr *MockPackServiceMockRecorder) ListDeletedPacks(ctx any) *MockPackServiceListDeletedPacksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedPacks", reflect.TypeOf((*MockPackService)(nil).ListDeletedPacks), ctx)
+	return &MockPackServiceListDeletedPacksCall{Call: call}
+}
+
+// MockPackServiceListDeletedPacksCall wrap *gomock.Call
+type MockPackServiceListDeletedPacksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackServiceListDeletedPacksCall) Return(arg0 []model.Pack, arg1 error) *MockPackServiceListDeletedPacksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackServiceListDeletedPacksCall) Do(f func(context.Context) ([]model.Pack, error)) *MockPackServiceListDeletedPacksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackServiceListDeletedPacksCall) DoAndReturn(f func(context.Context) ([]model.Pack, error)) *MockPackServiceListDeletedPacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListPacks mocks base method.
+func (m *MockPackService) ListPacks(ctx context.Context, query store.PackQuery) (*store.PackPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPacks", ctx, query)
+	ret0, _ := ret[0].(*store.PackPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // ListPacks indicates an expected call of ListPacks.
-func (mr *MockPackServiceMockRecorder) ListPacks(ctx any) *MockPackServiceListPacksCall {
+func (mr *MockPackServiceMockRecorder) ListPacks(ctx, query any) *MockPackServiceListPacksCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPacks", reflect.TypeOf((*MockPackService)(nil).ListPacks), ctx)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPacks", reflect.TypeOf((*MockPackService)(nil).ListPacks), ctx, query)
 	return &MockPackServiceListPacksCall{Call: call}
 }
 
@@ -223,19 +310,97 @@ type MockPackServiceListPacksCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockPackServiceListPacksCall) Return(arg0 []model.Pack, arg1 error) *MockPackServiceListPacksCall {
+func (c *MockPackServiceListPacksCall) Return(arg0 *store.PackPage, arg1 error) *MockPackServiceListPacksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackServiceListPacksCall) Do(f func(context.Context, store.PackQuery) (*store.PackPage, error)) *MockPackServiceListPacksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackServiceListPacksCall) DoAndReturn(f func(context.Context, store.PackQuery) (*store.PackPage, error)) *MockPackServiceListPacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgePacks mocks base method.
+func (m *MockPackService) PurgePacks(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgePacks", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgePacks indicates an expected call of PurgePacks.
+func (mr *MockPackServiceMockRecorder) PurgePacks(ctx, before any) *MockPackServicePurgePacksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgePacks", reflect.TypeOf((*MockPackService)(nil).PurgePacks), ctx, before)
+	return &MockPackServicePurgePacksCall{Call: call}
+}
+
+// MockPackServicePurgePacksCall wrap *gomock.Call
+type MockPackServicePurgePacksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackServicePurgePacksCall) Return(arg0 int64, arg1 error) *MockPackServicePurgePacksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackServicePurgePacksCall) Do(f func(context.Context, time.Time) (int64, error)) *MockPackServicePurgePacksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackServicePurgePacksCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockPackServicePurgePacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RestorePack mocks base method.
+func (m *MockPackService) RestorePack(ctx context.Context, id string) (*model.Pack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePack", ctx, id)
+	ret0, _ := ret[0].(*model.Pack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestorePack indicates an expected call of RestorePack.
+func (mr *MockPackServiceMockRecorder) RestorePack(ctx, id any) *MockPackServiceRestorePackCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePack", reflect.TypeOf((*MockPackService)(nil).RestorePack), ctx, id)
+	return &MockPackServiceRestorePackCall{Call: call}
+}
+
+// MockPackServiceRestorePackCall wrap *gomock.Call
+type MockPackServiceRestorePackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackServiceRestorePackCall) Return(arg0 *model.Pack, arg1 error) *MockPackServiceRestorePackCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockPackServiceListPacksCall) Do(f func(context.Context) ([]model.Pack, error)) *MockPackServiceListPacksCall {
+func (c *MockPackServiceRestorePackCall) Do(f func(context.Context, string) (*model.Pack, error)) *MockPackServiceRestorePackCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockPackServiceListPacksCall) DoAndReturn(f func(context.Context) ([]model.Pack, error)) *MockPackServiceListPacksCall {
+func (c *MockPackServiceRestorePackCall) DoAndReturn(f func(context.Context, string) (*model.Pack, error)) *MockPackServiceRestorePackCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kliuchnikovv/packulator/internal/model"
@@ -15,16 +17,27 @@ import (
 
 // PackService defines the interface for pack configuration management operations.
 type PackService interface {
-	// CreatePacks creates a new pack configuration with the given pack sizes
-	CreatePacks(ctx context.Context, packs ...int64) (string, error)
+	// CreatePacks creates a pack configuration with the given pack sizes unless it exists,
+	// returning its version hash and whether it was created
+	CreatePacks(ctx context.Context, packs ...int64) (string, bool, error)
+	// CreatePackItems creates a pack configuration with the given pack items unless it exists,
+	// returning its version hash and whether it was created
+	CreatePackItems(ctx context.Context, items ...model.PackItem) (string, bool, error)
 	// GetPackByID retrieves a pack configuration by its unique ID
 	GetPackByID(ctx context.Context, id string) (*model.Pack, error)
 	// GetPackByHash retrieves a pack configuration by its version hash
 	GetPackByHash(ctx context.Context, hash string) (*model.Pack, error)
-	// ListPacks returns all available pack configurations
-	ListPacks(ctx context.Context) ([]model.Pack, error)
+	// ListPacks returns a page of pack configurations filtered and sorted by the query
+	ListPacks(ctx context.Context, query store.PackQuery) (*store.PackPage, error)
 	// DeletePack removes a pack configuration by its unique ID
 	DeletePack(ctx context.Context, id string) error
+	// ListDeletedPacks returns deleted pack configurations, the most recently deleted first
+	ListDeletedPacks(ctx context.Context) ([]model.Pack, error)
+	// RestorePack undeletes a deleted pack configuration by its unique ID and returns it
+	RestorePack(ctx context.Context, id string) (*model.Pack, error)
+	// PurgePacks permanently deletes pack configurations deleted before the time with their items,
+	// returning the number of purged packs
+	PurgePacks(ctx context.Context, before time.Time) (int64, error)
 }
 
 // packService implements the PackService interface.
@@ -39,33 +52,59 @@ func NewPackService(store store.Store) PackService {
 	}
 }
 
-// CreatePacks creates a new pack configuration from the provided pack sizes.
-// It generates a unique version hash and stores the pack configuration in the database.
-func (s *packService) CreatePacks(ctx context.Context, packs ...int64) (string, error) {
+// CreatePacks creates a pack configuration from the provided pack sizes.
+// It generates a unique version hash and stores the pack configuration in the database
+// unless a configuration with the same hash exists, reporting whether it was created.
+func (s *packService) CreatePacks(ctx context.Context, packs ...int64) (string, bool, error) {
+	var items = make([]model.PackItem, len(packs))
+	for i, size := range packs {
+		items[i] = model.PackItem{Size: size}
+	}
+
+	return s.CreatePackItems(ctx, items...)
+}
+
+// CreatePackItems creates a pack configuration from the provided pack items.
+// Only sizes, costs, weights, volumes and quantity rules of items are used, identifiers are generated.
+// Creating a configuration with the hash of an existing one returns that one and reports
+// it wasn't created. Non-positive sizes are rejected with ErrInvalidPackSize.
+func (s *packService) CreatePackItems(ctx context.Context, items ...model.PackItem) (string, bool, error) {
+	for _, item := range items {
+		if item.Size <= 0 {
+			return "", false, fmt.Errorf("%w: %d", ErrInvalidPackSize, item.Size)
+		}
+	}
+
 	// Create pack model with unique ID and version hash
 	var pack = model.Pack{
 		ID:          uuid.NewString(),
-		VersionHash: generateVersionHash(packs),
-		PackItems:   make([]model.PackItem, len(packs)),
+		VersionHash: generateVersionHash(items),
+		PackItems:   make([]model.PackItem, len(items)),
 	}
 
-	// Create pack items for each provided size
-	for i, size := range packs {
-		pack.TotalAmount += size
+	// Create pack items for each provided item
+	for i, item := range items {
+		pack.TotalAmount += item.Size
 		pack.PackItems[i] = model.PackItem{
-			ID:     uuid.NewString(),
-			PackID: pack.ID,
-			Size:   size,
+			ID:       uuid.NewString(),
+			PackID:   pack.ID,
+			Size:     item.Size,
+			Cost:     item.Cost,
+			Weight:   item.Weight,
+			Volume:   item.Volume,
+			MinCount: item.MinCount,
+			MaxCount: item.MaxCount,
+			Step:     item.Step,
 		}
 	}
 
-	// Persist pack configuration to database
-	err := s.store.SavePacks(ctx, pack)
+	// Persist pack configuration to database unless it exists
+	created, err := s.store.SavePackOnce(ctx, &pack)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	return pack.VersionHash, nil
+	return pack.VersionHash, created, nil
 }
 
 // GetPackByID retrieves a pack configuration by its unique identifier.
@@ -78,9 +117,9 @@ func (s *packService) GetPackByHash(ctx context.Context, hash string) (*model.Pa
 	return s.store.GetPackByHash(ctx, hash)
 }
 
-// ListPacks returns all available pack configurations.
-func (s *packService) ListPacks(ctx context.Context) ([]model.Pack, error) {
-	return s.store.ListPacks(ctx)
+// ListPacks returns a page of pack configurations filtered and sorted by the query.
+func (s *packService) ListPacks(ctx context.Context, query store.PackQuery) (*store.PackPage, error) {
+	return s.store.ListPacks(ctx, query)
 }
 
 // DeletePack removes a pack configuration by its unique identifier.
@@ -88,18 +127,55 @@ func (s *packService) DeletePack(ctx context.Context, id string) error {
 	return s.store.DeletePack(ctx, id)
 }
 
-// generateVersionHash creates a deterministic hash from pack sizes.
-// It sorts the packs first to ensure the same combination always produces the same hash.
-func generateVersionHash(packs []int64) string {
-	// Sort packs to ensure deterministic hashing
-	sorted := make([]int64, len(packs))
-	copy(sorted, packs)
-	slices.Sort(sorted)
+// ListDeletedPacks returns deleted pack configurations, the most recently deleted first.
+func (s *packService) ListDeletedPacks(ctx context.Context) ([]model.Pack, error) {
+	return s.store.ListDeletedPacks(ctx)
+}
+
+// RestorePack undeletes a deleted pack configuration by its unique identifier.
+func (s *packService) RestorePack(ctx context.Context, id string) (*model.Pack, error) {
+	return s.store.RestorePack(ctx, id)
+}
 
-	// Generate SHA-256 hash from sorted pack sizes
+// PurgePacks permanently deletes pack configurations deleted before the time with their items.
+func (s *packService) PurgePacks(ctx context.Context, before time.Time) (int64, error) {
+	return s.store.PurgePacks(ctx, before)
+}
+
+// generateVersionHash creates a deterministic hash from pack sizes, costs, measures and quantity rules.
+// It sorts the items first to ensure the same combination always produces the same hash.
+// Items without cost are hashed by size only, items without weight and volume by size
+// and cost and items without rules by size, cost and measures, so configurations created
+// before costs, measures or rules were introduced keep their hashes.
+func generateVersionHash(items []model.PackItem) string {
+	// Sort items to ensure deterministic hashing
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b model.PackItem) int {
+		return cmp.Or(
+			cmp.Compare(a.Size, b.Size),
+			cmp.Compare(a.Cost, b.Cost),
+			cmp.Compare(a.Weight, b.Weight),
+			cmp.Compare(a.Volume, b.Volume),
+			cmp.Compare(a.MinCount, b.MinCount),
+			cmp.Compare(a.MaxCount, b.MaxCount),
+			cmp.Compare(a.Step, b.Step),
+		)
+	})
+
+	// Generate SHA-256 hash from sorted pack sizes, costs, measures and rules
 	hash := sha256.New()
-	for _, pack := range sorted {
-		hash.Write(fmt.Appendf(nil, "%d,", pack))
+	for _, item := range sorted {
+		switch {
+		case item.MinCount != 0 || item.MaxCount != 0 || item.Step != 0:
+			hash.Write(fmt.Appendf(nil, "%d:%d:%d:%d:%d:%d:%d,", item.Size, item.Cost, item.Weight, item.Volume,
+				item.MinCount, item.MaxCount, item.Step))
+		case item.Weight != 0 || item.Volume != 0:
+			hash.Write(fmt.Appendf(nil, "%d:%d:%d:%d,", item.Size, item.Cost, item.Weight, item.Volume))
+		case item.Cost != 0:
+			hash.Write(fmt.Appendf(nil, "%d:%d,", item.Size, item.Cost))
+		default:
+			hash.Write(fmt.Appendf(nil, "%d,", item.Size))
+		}
 	}
 
 	// Return first 16 characters of hex-encoded hash
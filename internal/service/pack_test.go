@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/kliuchnikovv/packulator/internal/model"
 	"github.com/kliuchnikovv/packulator/internal/store"
@@ -29,13 +32,14 @@ func TestPackService_CreatePacks(t *testing.T) {
 
 		packs := []int64{250, 500, 1000}
 
-		// Mock SavePacks to return success
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(nil)
+		// Mock SavePackOnce to return success
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(true, nil)
 
-		versionHash, err := service.CreatePacks(ctx, packs...)
+		versionHash, created, err := service.CreatePacks(ctx, packs...)
 
 		require.NoError(t, err)
+		assert.True(t, created)
 		assert.NotEmpty(t, versionHash)
 		assert.Len(t, versionHash, 16) // Hash is truncated to 16 characters
 	})
@@ -48,48 +52,117 @@ func TestPackService_CreatePacks(t *testing.T) {
 		packs := []int64{250, 500}
 		expectedError := errors.New("database error")
 
-		// Mock SavePacks to return error
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(expectedError)
+		// Mock SavePackOnce to return error
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(false, expectedError)
 
-		versionHash, err := service.CreatePacks(ctx, packs...)
+		versionHash, _, err := service.CreatePacks(ctx, packs...)
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedError, err)
 		assert.Empty(t, versionHash)
 	})
 
+	t.Run("existing configuration", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewPackService(mockStore)
+		ctx := context.Background()
+
+		var id string
+
+		// Mock SavePackOnce to load the pack saved before
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pack *model.Pack) (bool, error) {
+				id = pack.ID
+				*pack = model.Pack{ID: "pack-1", VersionHash: pack.VersionHash}
+				return false, nil
+			})
+
+		versionHash, created, err := service.CreatePacks(ctx, 250, 500)
+
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.NotEqual(t, "pack-1", id)
+		assert.Equal(t, generateVersionHash([]model.PackItem{{Size: 500}, {Size: 250}}), versionHash)
+	})
+
 	t.Run("empty packs", func(t *testing.T) {
 		mockStore := mock_store.NewMockStore(gomock.NewController(t))
 		service := NewPackService(mockStore)
 		ctx := context.Background()
 
-		// Mock SavePacks with empty slice
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(nil)
+		// Mock SavePackOnce with empty slice
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(true, nil)
 
-		versionHash, err := service.CreatePacks(ctx)
+		versionHash, _, err := service.CreatePacks(ctx)
 
 		require.NoError(t, err)
 		assert.NotEmpty(t, versionHash)
 	})
 
+	t.Run("invalid pack size", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewPackService(mockStore)
+		ctx := context.Background()
+
+		versionHash, _, err := service.CreatePacks(ctx, 250, 0)
+
+		assert.ErrorIs(t, err, ErrInvalidPackSize)
+		assert.Empty(t, versionHash)
+	})
+
 	t.Run("single pack", func(t *testing.T) {
 		mockStore := mock_store.NewMockStore(gomock.NewController(t))
 		service := NewPackService(mockStore)
 		ctx := context.Background()
 
-		// Mock SavePacks
-		mockStore.EXPECT().SavePacks(gomock.Any(), gomock.Any()).
-			Return(nil)
+		// Mock SavePackOnce
+		mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+			Return(true, nil)
 
-		versionHash, err := service.CreatePacks(ctx, 1000)
+		versionHash, _, err := service.CreatePacks(ctx, 1000)
 
 		require.NoError(t, err)
 		assert.NotEmpty(t, versionHash)
 	})
 }
 
+func TestPackService_CreatePackItems(t *testing.T) {
+	mockStore := mock_store.NewMockStore(gomock.NewController(t))
+	service := NewPackService(mockStore)
+	ctx := context.Background()
+
+	items := []model.PackItem{
+		{Size: 250, Cost: 3},
+		{Size: 500, Weight: 7, Volume: 2},
+	}
+
+	var saved model.Pack
+	mockStore.EXPECT().SavePackOnce(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pack *model.Pack) (bool, error) {
+			saved = *pack
+			return true, nil
+		})
+
+	versionHash, _, err := service.CreatePackItems(ctx, items...)
+
+	require.NoError(t, err)
+	assert.Equal(t, generateVersionHash(items), versionHash)
+	assert.Equal(t, versionHash, saved.VersionHash)
+	assert.Equal(t, int64(750), saved.TotalAmount)
+	require.Len(t, saved.PackItems, 2)
+
+	for i, item := range saved.PackItems {
+		assert.NotEmpty(t, item.ID)
+		assert.Equal(t, saved.ID, item.PackID)
+		assert.Equal(t, items[i].Size, item.Size)
+		assert.Equal(t, items[i].Cost, item.Cost)
+		assert.Equal(t, items[i].Weight, item.Weight)
+		assert.Equal(t, items[i].Volume, item.Volume)
+	}
+}
+
 func TestPackService_GetPackByID(t *testing.T) {
 	t.Run("successful retrieval", func(t *testing.T) {
 		mockStore := mock_store.NewMockStore(gomock.NewController(t))
@@ -134,25 +207,29 @@ func TestPackService_ListPacks(t *testing.T) {
 		service := NewPackService(mockStore)
 		ctx := context.Background()
 
-		expectedPacks := []model.Pack{
-			{
-				ID:          "pack-1",
-				VersionHash: "abc123",
-				TotalAmount: 250,
-			},
-			{
-				ID:          "pack-2",
-				VersionHash: "def456",
-				TotalAmount: 500,
+		query := store.PackQuery{SortBy: store.SortByTotalAmount, Descending: true, Limit: 2}
+		expectedPage := &store.PackPage{
+			Packs: []model.Pack{
+				{
+					ID:          "pack-2",
+					VersionHash: "def456",
+					TotalAmount: 500,
+				},
+				{
+					ID:          "pack-1",
+					VersionHash: "abc123",
+					TotalAmount: 250,
+				},
 			},
+			NextCursor: "cursor",
 		}
 
-		mockStore.EXPECT().ListPacks(gomock.Any()).Return(expectedPacks, nil)
+		mockStore.EXPECT().ListPacks(gomock.Any(), query).Return(expectedPage, nil)
 
-		result, err := service.ListPacks(ctx)
+		result, err := service.ListPacks(ctx, query)
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedPacks, result)
+		assert.Equal(t, expectedPage, result)
 	})
 
 	t.Run("empty list", func(t *testing.T) {
@@ -160,12 +237,13 @@ func TestPackService_ListPacks(t *testing.T) {
 		service := NewPackService(mockStore)
 		ctx := context.Background()
 
-		mockStore.EXPECT().ListPacks(gomock.Any()).Return(nil, nil)
+		mockStore.EXPECT().ListPacks(gomock.Any(), gomock.Any()).Return(&store.PackPage{}, nil)
 
-		result, err := service.ListPacks(ctx)
+		result, err := service.ListPacks(ctx, store.PackQuery{})
 
 		require.NoError(t, err)
-		assert.Empty(t, result)
+		assert.Empty(t, result.Packs)
+		assert.Empty(t, result.NextCursor)
 	})
 
 	t.Run("store error", func(t *testing.T) {
@@ -175,9 +253,9 @@ func TestPackService_ListPacks(t *testing.T) {
 
 		expectedError := errors.New("database error")
 
-		mockStore.EXPECT().ListPacks(gomock.Any()).Return(nil, expectedError)
+		mockStore.EXPECT().ListPacks(gomock.Any(), gomock.Any()).Return(nil, expectedError)
 
-		result, err := service.ListPacks(ctx)
+		result, err := service.ListPacks(ctx, store.PackQuery{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedError, err)
@@ -214,12 +292,57 @@ func TestPackService_DeletePack(t *testing.T) {
 	})
 }
 
+func TestPackService_DeletedPacks(t *testing.T) {
+	t.Run("list deleted packs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewPackService(mockStore)
+		ctx := context.Background()
+
+		expectedPacks := []model.Pack{{ID: "pack-1", VersionHash: "abc123"}}
+
+		mockStore.EXPECT().ListDeletedPacks(gomock.Any()).Return(expectedPacks, nil)
+
+		result, err := service.ListDeletedPacks(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedPacks, result)
+	})
+
+	t.Run("restore pack", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewPackService(mockStore)
+		ctx := context.Background()
+
+		mockStore.EXPECT().RestorePack(gomock.Any(), "pack-1").Return(nil, store.ErrConflict)
+
+		result, err := service.RestorePack(ctx, "pack-1")
+
+		assert.ErrorIs(t, err, store.ErrConflict)
+		assert.Nil(t, result)
+	})
+
+	t.Run("purge packs", func(t *testing.T) {
+		mockStore := mock_store.NewMockStore(gomock.NewController(t))
+		service := NewPackService(mockStore)
+		ctx := context.Background()
+
+		before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockStore.EXPECT().PurgePacks(gomock.Any(), before).Return(int64(3), nil)
+
+		purged, err := service.PurgePacks(ctx, before)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+	})
+}
+
 func TestGenerateVersionHash(t *testing.T) {
 	t.Run("consistent hash for same input", func(t *testing.T) {
 		packs := []int64{250, 500, 1000}
 
-		hash1 := generateVersionHash(packs)
-		hash2 := generateVersionHash(packs)
+		hash1 := generateVersionHash(packItems(packs...))
+		hash2 := generateVersionHash(packItems(packs...))
 
 		assert.Equal(t, hash1, hash2)
 		assert.Len(t, hash1, 16) // Truncated to 16 characters
@@ -229,8 +352,8 @@ func TestGenerateVersionHash(t *testing.T) {
 		packs1 := []int64{250, 500, 1000}
 		packs2 := []int64{250, 500, 2000}
 
-		hash1 := generateVersionHash(packs1)
-		hash2 := generateVersionHash(packs2)
+		hash1 := generateVersionHash(packItems(packs1...))
+		hash2 := generateVersionHash(packItems(packs2...))
 
 		assert.NotEqual(t, hash1, hash2)
 	})
@@ -239,8 +362,8 @@ func TestGenerateVersionHash(t *testing.T) {
 		packs1 := []int64{250, 500, 1000}
 		packs2 := []int64{1000, 250, 500}
 
-		hash1 := generateVersionHash(packs1)
-		hash2 := generateVersionHash(packs2)
+		hash1 := generateVersionHash(packItems(packs1...))
+		hash2 := generateVersionHash(packItems(packs2...))
 
 		assert.Equal(t, hash1, hash2, "hash should be same regardless of input order")
 	})
@@ -248,7 +371,7 @@ func TestGenerateVersionHash(t *testing.T) {
 	t.Run("empty input", func(t *testing.T) {
 		packs := []int64{}
 
-		hash := generateVersionHash(packs)
+		hash := generateVersionHash(packItems(packs...))
 
 		assert.NotEmpty(t, hash)
 		assert.Len(t, hash, 16)
@@ -257,7 +380,7 @@ func TestGenerateVersionHash(t *testing.T) {
 	t.Run("single pack", func(t *testing.T) {
 		packs := []int64{1000}
 
-		hash := generateVersionHash(packs)
+		hash := generateVersionHash(packItems(packs...))
 
 		assert.NotEmpty(t, hash)
 		assert.Len(t, hash, 16)
@@ -267,19 +390,102 @@ func TestGenerateVersionHash(t *testing.T) {
 		packs1 := []int64{250, 250, 500}
 		packs2 := []int64{500, 250, 250}
 
-		hash1 := generateVersionHash(packs1)
-		hash2 := generateVersionHash(packs2)
+		hash1 := generateVersionHash(packItems(packs1...))
+		hash2 := generateVersionHash(packItems(packs2...))
 
 		assert.Equal(t, hash1, hash2, "hash should handle duplicates correctly")
 	})
 }
 
+func TestGenerateVersionHash_Costs(t *testing.T) {
+	t.Run("hash without costs is unchanged", func(t *testing.T) {
+		expected := fmt.Sprintf("%x", sha256.Sum256([]byte("250,500,1000,")))[:16]
+
+		assert.Equal(t, expected, generateVersionHash(packItems(1000, 250, 500)))
+	})
+
+	t.Run("different hash for different costs", func(t *testing.T) {
+		hash1 := generateVersionHash([]model.PackItem{{Size: 250, Cost: 1}, {Size: 500}})
+		hash2 := generateVersionHash([]model.PackItem{{Size: 250, Cost: 2}, {Size: 500}})
+		hash3 := generateVersionHash(packItems(250, 500))
+
+		assert.NotEqual(t, hash1, hash2)
+		assert.NotEqual(t, hash1, hash3)
+	})
+
+	t.Run("same hash for reordered items", func(t *testing.T) {
+		hash1 := generateVersionHash([]model.PackItem{{Size: 250, Cost: 1}, {Size: 500, Cost: 3}})
+		hash2 := generateVersionHash([]model.PackItem{{Size: 500, Cost: 3}, {Size: 250, Cost: 1}})
+
+		assert.Equal(t, hash1, hash2)
+	})
+}
+
+func TestGenerateVersionHash_Measures(t *testing.T) {
+	t.Run("hash without measures is unchanged", func(t *testing.T) {
+		expected := fmt.Sprintf("%x", sha256.Sum256([]byte("250:1,500,")))[:16]
+
+		assert.Equal(t, expected, generateVersionHash([]model.PackItem{{Size: 500}, {Size: 250, Cost: 1}}))
+	})
+
+	t.Run("different hash for different measures", func(t *testing.T) {
+		hash1 := generateVersionHash([]model.PackItem{{Size: 250, Weight: 1}, {Size: 500}})
+		hash2 := generateVersionHash([]model.PackItem{{Size: 250, Volume: 1}, {Size: 500}})
+		hash3 := generateVersionHash(packItems(250, 500))
+
+		assert.NotEqual(t, hash1, hash2)
+		assert.NotEqual(t, hash1, hash3)
+		assert.NotEqual(t, hash2, hash3)
+	})
+}
+
+func TestGenerateVersionHash_Rules(t *testing.T) {
+	t.Run("hash without rules is unchanged", func(t *testing.T) {
+		expected := fmt.Sprintf("%x", sha256.Sum256([]byte("250:0:2:0,500,")))[:16]
+
+		assert.Equal(t, expected, generateVersionHash([]model.PackItem{{Size: 500}, {Size: 250, Weight: 2}}))
+	})
+
+	t.Run("different hash for different rules", func(t *testing.T) {
+		var hashes = []string{
+			generateVersionHash(packItems(250, 500)),
+			generateVersionHash([]model.PackItem{{Size: 250, MinCount: 4}, {Size: 500}}),
+			generateVersionHash([]model.PackItem{{Size: 250, MaxCount: 4}, {Size: 500}}),
+			generateVersionHash([]model.PackItem{{Size: 250, Step: 4}, {Size: 500}}),
+			generateVersionHash([]model.PackItem{{Size: 250}, {Size: 500, Step: 4}}),
+		}
+
+		for i := range hashes {
+			for j := range i {
+				assert.NotEqual(t, hashes[i], hashes[j], "hashes %d and %d", i, j)
+			}
+		}
+	})
+
+	t.Run("same hash regardless of order", func(t *testing.T) {
+		assert.Equal(t,
+			generateVersionHash([]model.PackItem{{Size: 250, Step: 4}, {Size: 500, MinCount: 2}}),
+			generateVersionHash([]model.PackItem{{Size: 500, MinCount: 2}, {Size: 250, Step: 4}}),
+		)
+	})
+}
+
+// packItems returns pack items of the given sizes without costs.
+func packItems(sizes ...int64) []model.PackItem {
+	var items = make([]model.PackItem, len(sizes))
+	for i, size := range sizes {
+		items[i] = model.PackItem{Size: size}
+	}
+
+	return items
+}
+
 // Benchmark tests
 func BenchmarkGenerateVersionHash(b *testing.B) {
 	packs := []int64{250, 500, 1000, 2000, 5000}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		generateVersionHash(packs)
+		generateVersionHash(packItems(packs...))
 	}
 }
@@ -3,99 +3,726 @@ package service
 import (
 	"context"
 	"errors"
-	"maps"
+	"fmt"
+	"math"
 	"slices"
+	"time"
 )
 
+// Calculation errors
+var (
+	ErrInsufficientStock  = errors.New("not enough packs in stock to fulfil the order") // Returned when stock can't cover the amount
+	ErrNoCombination      = errors.New("no combination satisfies the strategy")         // Returned when the strategy accepts no combination
+	ErrUnsupportedOptions = errors.New("options can't be combined")                     // Returned when options can't be calculated together
+)
+
+// maxPackSizes is the number of distinct pack sizes a table can index.
+const maxPackSizes = math.MaxUint8
+
+// variant describes the best known way to reach a particular sum.
 type variant struct {
-	combination   map[int64]int64
-	numberOfPacks int64
-	overshoot     int64
+	Score
+
+	sum int64
+}
+
+// solution is a computed dynamic programming state describing reachable sums.
+type solution interface {
+	// variant returns the best way to reach the sum or nil if it's unreachable
+	variant(w window, sum int64) *variant
+	// combination rebuilds the packs used to reach the sum
+	combination(sum int64) map[int64]int64
+}
+
+// tableConfig describes what a table is computed for.
+type tableConfig struct {
+	packs     []int64     // Sorted normalized pack sizes
+	unitCosts []int64     // Costs of packs by index, nil if cost isn't tracked
+	criteria  []Criterion // Criteria comparing combinations of the same sum
+	maxPacks  int64       // Largest number of packs kept for every sum, zero if only the best combination is kept
+}
+
+// key returns a string identifying tables computed for the config.
+func (c tableConfig) key() string {
+	return fmt.Sprint(c.packs, c.unitCosts, c.criteria, c.maxPacks)
+}
+
+// table holds the dynamic programming state of a calculation.
+// Instead of keeping a full combination for every sum it stores the best
+// combination's measures and the pack added last, so combinations are rebuilt on demand.
+type table struct {
+	tableConfig
+
+	counts []uint32 // Number of packs of the best combination adding up to the sum
+	costs  []int64  // Cost of the best combination adding up to the sum, nil if cost isn't tracked
+	last   []uint8  // 1-based index of the last pack added to reach the sum, 0 if unreachable
+}
+
+// newTable builds the table for all sums from zero up to maxRange.
+func newTable(config tableConfig, maxRange int64, stop *checkpoint) (*table, error) {
+	var t = &table{
+		tableConfig: config,
+		counts:      make([]uint32, maxRange+1),
+		last:        make([]uint8, maxRange+1),
+	}
+
+	if config.unitCosts != nil {
+		t.costs = make([]int64, maxRange+1)
+	}
+
+	if err := t.fill(1, stop); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// fill computes table entries starting from the given sum.
+// Every sum takes the best of its predecessors; packs are tried from the largest
+// so that among equally good combinations the one ending with the largest pack wins.
+// Filling stops with an error if the calculation is stopped, leaving the rest of the table empty.
+func (t *table) fill(from int64, stop *checkpoint) error {
+	for sum := from; sum < int64(len(t.last)); sum++ {
+		if err := stop.step(); err != nil {
+			return err
+		}
+
+		for i := len(t.packs) - 1; i >= 0; i-- {
+			var prev = sum - t.packs[i]
+			if prev < 0 || prev >= sum || !t.reachable(prev) {
+				continue
+			}
+
+			var (
+				count = t.counts[prev] + 1
+				cost  int64
+			)
+
+			if t.costs != nil {
+				cost = t.costs[prev] + t.unitCosts[i]
+			}
+
+			if t.last[sum] == 0 || isBetterSum(t.criteria, count, t.counts[sum], cost, t.cost(sum)) {
+				t.counts[sum] = count
+				t.last[sum] = uint8(i + 1)
+
+				if t.costs != nil {
+					t.costs[sum] = cost
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// extended returns a table covering sums up to maxRange. Already computed entries are
+// shared with the original table, which stays valid for readers, and only new sums are filled.
+func (t *table) extended(maxRange int64, stop *checkpoint) (*table, error) {
+	var (
+		from     = int64(len(t.last))
+		extended = &table{
+			tableConfig: t.tableConfig,
+			counts:      slices.Grow(t.counts, int(maxRange+1-from))[:maxRange+1],
+			last:        slices.Grow(t.last, int(maxRange+1-from))[:maxRange+1],
+		}
+	)
+
+	if t.costs != nil {
+		extended.costs = slices.Grow(t.costs, int(maxRange+1-from))[:maxRange+1]
+	}
+
+	if err := extended.fill(from, stop); err != nil {
+		return nil, err
+	}
+
+	return extended, nil
+}
+
+// bytesPerSum returns memory a table of the config takes for every sum.
+func (c tableConfig) bytesPerSum() int64 {
+	var bytes int64 = 4 + 1 // Number of packs and the last pack
+	if c.unitCosts != nil {
+		bytes += 8
+	}
+
+	return bytes
+}
+
+// maxRange returns the largest sum covered by the table.
+func (t *table) maxRange() int64 {
+	return int64(len(t.last)) - 1
+}
+
+// size returns approximate memory occupied by the table in bytes.
+func (t *table) size() int64 {
+	return int64(cap(t.counts))*4 + int64(cap(t.costs))*8 + int64(cap(t.last)) +
+		int64(len(t.packs)+len(t.unitCosts))*8
+}
+
+// reachable reports whether the sum can be composed of available packs.
+func (t *table) reachable(sum int64) bool {
+	return sum == 0 || t.last[sum] != 0
+}
+
+// cost returns the cost of the best combination for the sum, zero if cost isn't tracked.
+func (t *table) cost(sum int64) int64 {
+	if t.costs == nil {
+		return 0
+	}
+
+	return t.costs[sum]
+}
+
+// variant returns the best way to reach the sum or nil if it's unreachable.
+func (t *table) variant(w window, sum int64) *variant {
+	if !t.reachable(sum) {
+		return nil
+	}
+
+	return &variant{
+		sum: sum,
+		Score: Score{
+			Overshoot: w.overshoot(sum),
+			Packs:     int64(t.counts[sum]),
+			Cost:      t.cost(sum),
+		},
+	}
+}
+
+// combination rebuilds the packs used to reach the sum by following last packs back to zero.
+func (t *table) combination(sum int64) map[int64]int64 {
+	var result = make(map[int64]int64)
+
+	for sum > 0 {
+		var pack = t.packs[t.last[sum]-1]
+
+		result[pack]++
+		sum -= pack
+	}
+
+	return result
+}
+
+// Option configures a calculation.
+type Option func(*options)
+
+// options holds settings applied to a calculation.
+type options struct {
+	cache    *TableCache     // Cache of tables shared between calculations
+	stock    map[int64]int64 // Number of available packs by size, nil if unlimited
+	costs    map[int64]int64 // Cost of a single pack by size
+	strategy Strategy        // Strategy choosing the optimal combination
+	under    Tolerance       // Allowed shortfall below the amount
+	over     Tolerance       // Allowed excess above the amount not counted as overshoot
+	maxPacks int64           // Largest number of packs of a combination, zero if unlimited
+	rules    map[int64]Rule  // Rules restricting numbers of packs by size, nil if unrestricted
+	budget   time.Duration   // Time limit of a calculation, zero if unlimited
+	input    InputLimits     // Limits of inputs of a calculation
+}
+
+// WithCache makes calculation reuse and extend tables kept in the cache.
+// Nil cache disables caching.
+func WithCache(cache *TableCache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
 }
 
+// WithStock limits the number of packs of each size to the available stock.
+// Sizes absent from the stock are unlimited.
+func WithStock(stock map[int64]int64) Option {
+	return func(o *options) {
+		o.stock = stock
+	}
+}
+
+// WithCosts sets the cost of a single pack by size. Sizes absent from costs are free.
+func WithCosts(costs map[int64]int64) Option {
+	return func(o *options) {
+		o.costs = costs
+	}
+}
+
+// WithStrategy sets the strategy choosing the optimal combination, LeastOvershoot by default.
+func WithStrategy(strategy Strategy) Option {
+	return func(o *options) {
+		o.strategy = strategy
+	}
+}
+
+// WithTolerance lets the calculation ship fewer items than the amount within the under
+// tolerance and doesn't count items above the amount within the over tolerance as overshoot.
+// Among equally good combinations the one closest to the amount is chosen.
+func WithTolerance(under, over Tolerance) Option {
+	return func(o *options) {
+		o.under = under
+		o.over = over
+	}
+}
+
+// NumberOfPacks calculates the combination of packs covering the amount which is optimal
+// for the strategy. By default it looks for the least overshoot, preferring fewer packs
+// when overshoot is equal.
 func NumberOfPacks(
 	ctx context.Context,
 	amount int64,
 	packs []int64,
+	opts ...Option,
 ) (map[int64]int64, error) {
-	if amount <= 0 || len(packs) == 0 {
-		return map[int64]int64{}, nil
+	return numberOfPacks(ctx, amount, packs, newOptions(opts))
+}
+
+// numberOfPacks calculates the optimal combination of packs covering the amount with options applied.
+func numberOfPacks(ctx context.Context, amount int64, packs []int64, options options) (map[int64]int64, error) {
+	combinations, err := rankCombinations(ctx, amount, packs, 1, options)
+	if err != nil {
+		return nil, err
 	}
 
-	slices.Sort(packs)
+	return combinations[0], nil
+}
+
+// newOptions applies options over the defaults.
+func newOptions(opts []Option) options {
+	var options = options{strategy: LeastOvershoot()}
+	for _, option := range opts {
+		option(&options)
+	}
+
+	return options
+}
+
+// selectVariants picks variants among sums of the window up to maxRange to rebuild combinations of.
+// Bulk packs taken analytically must be added to scores of variants.
+type selectVariants func(w window, maxRange, bulk int64, solution solution) []*variant
+
+// rankCombinations returns up to limit combinations of packs covering the amount ranked
+// by the strategy from the optimal one, each the best combination of its shipped quantity.
+// At least one combination is returned unless there's an error.
+func rankCombinations(
+	ctx context.Context,
+	amount int64,
+	packs []int64,
+	limit int,
+	options options,
+) ([]map[int64]int64, error) {
+	return findCombinations(ctx, amount, packs, options.strategy.Criteria(), options,
+		func(w window, maxRange, bulk int64, solution solution) []*variant {
+			return rankVariants(options.strategy, w, maxRange, bulk, solution, limit)
+		},
+	)
+}
+
+// findCombinations computes the table of the best combinations of every sum by criteria
+// and returns combinations of variants picked by the selector. The calculation stops
+// with TimeoutError once the context is done or the budget runs out.
+// At least one combination is returned unless there's an error.
+func findCombinations(
+	ctx context.Context,
+	amount int64,
+	packs []int64,
+	criteria []Criterion,
+	options options,
+	selector selectVariants,
+) ([]map[int64]int64, error) {
+	if err := options.under.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := options.over.validate(); err != nil {
+		return nil, err
+	}
+
+	for pack, cost := range options.costs {
+		if cost < 0 {
+			return nil, fmt.Errorf("pack %d has negative cost: %d", pack, cost)
+		}
+	}
+
+	if options.maxPacks < 0 {
+		return nil, fmt.Errorf("maximum number of packs must not be negative: %d", options.maxPacks)
+	}
+
+	for pack, rule := range options.rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("pack %d: %w", pack, err)
+		}
+	}
+
+	var input = options.input.withDefaults()
+	if err := input.checkAmount(amount); err != nil {
+		return nil, err
+	}
+
+	normalized, divisor := normalizePacks(packs)
+	switch {
+	case amount <= 0 || len(packs) == 0:
+		return []map[int64]int64{{}}, nil
+	case len(normalized) == 0:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPackSize, packs)
+	}
+
+	if err := input.checkPackSizes(len(normalized)); err != nil {
+		return nil, err
+	}
+
+	packs = normalized
+
+	// Only multiples of the divisor can be shipped, so the window is rounded inwards
+	var window = newWindow(amount, divisor, options.under, options.over)
+
+	var config = tableConfig{
+		packs:    packs,
+		criteria: sumCriteria(criteria),
+	}
+
+	if slices.Contains(config.criteria, CriterionCost) {
+		config.unitCosts = normalizeCosts(options.costs, packs, divisor)
+	}
+
+	// Hide combinations of too many packs from the selector
+	var infeasible = func(err error) error { return err }
+	if options.maxPacks > 0 {
+		var counted bool
+		if config.criteria, counted = limitedCriteria(config.criteria); counted {
+			config.maxPacks = options.maxPacks
+		}
+
+		selector, infeasible = limitPacks(selector, options.maxPacks)
+	}
+
+	if options.budget > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, options.budget)
+		defer cancel()
+	}
+
+	var stop = newCheckpoint(ctx)
+	if err := stop.err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		combinations []map[int64]int64
+		err          error
+	)
 
 	var (
-		maxRange = amount + packs[len(packs)-1]
-		variants = make([]*variant, maxRange+1)
+		stock = normalizeStock(options.stock, packs, divisor)
+		rules = normalizeRules(options.rules, packs, divisor)
 	)
 
-	variants[0] = &variant{
-		numberOfPacks: 0,
-		overshoot:     -amount,
-		combination:   make(map[int64]int64),
+	if options.maxPacks > 0 {
+		dropLooseLimits(stock, rules, options.maxPacks)
 	}
 
-	// Dynamic programming: build all possible combinations
-	for sum := int64(0); sum <= maxRange; sum++ {
-		if variants[sum] == nil {
-			continue
+	if len(stock) > 0 || len(rules) > 0 {
+		combinations, err = calculateBounded(window, config, stock, rules, input, selector, stop)
+	} else {
+		combinations, err = calculate(window, config, options.cache, input, selector, stop)
+	}
+
+	if err != nil {
+		return nil, infeasible(err)
+	}
+
+	for i, combination := range combinations {
+		combinations[i] = denormalize(combination, divisor)
+	}
+
+	return combinations, nil
+}
+
+// calculate finds combinations of normalized packs with unlimited supply picked by the selector.
+func calculate(
+	w window,
+	config tableConfig,
+	cache *TableCache,
+	input InputLimits,
+	selector selectVariants,
+	stop *checkpoint,
+) ([]map[int64]int64, error) {
+	var (
+		largest  = config.packs[len(config.packs)-1]
+		bulk     int64
+		solution solution
+	)
+
+	// Fill the bulk of huge amounts with the largest pack analytically and run
+	// the dynamic programming only on the residual window. Periodicity holds only
+	// when the fewest packs are kept for every sum.
+	if slices.Equal(config.criteria, []Criterion{CriterionPacks}) {
+		bulk = bulkPacks(w.from, config.packs)
+	}
+
+	var (
+		residual = w.shifted(bulk * largest)
+		maxRange = saturatedAdd(residual.target, largest)
+	)
+
+	// Tables of huge amounts don't fit memory, counted tables keep every number of packs of a sum
+	var bytesPerSum = config.bytesPerSum()
+	if config.maxPacks > 0 {
+		bytesPerSum = config.countedBytesPerSum(maxRange)
+	}
+
+	if err := input.checkMemory(maxRange, bytesPerSum); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch {
+	case config.maxPacks > 0:
+		solution, err = newCountedTable(config, maxRange, stop)
+	case cache != nil:
+		solution, err = cache.table(config, maxRange, stop)
+	default:
+		solution, err = newTable(config, maxRange, stop)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var variants = selector(residual, maxRange, bulk, solution)
+	if len(variants) == 0 {
+		return nil, ErrNoCombination
+	}
+
+	var combinations = make([]map[int64]int64, len(variants))
+	for i, variant := range variants {
+		combinations[i] = solution.combination(variant.sum)
+		if bulk > 0 {
+			combinations[i][largest] += bulk
 		}
+	}
 
-		// Try adding each available pack size
-		for _, pack := range packs {
-			var newSum = sum + pack
-			if newSum > maxRange {
-				break // Optimization: packs are sorted, so we can break early
-			}
-			current := variants[sum]
+	return combinations, nil
+}
 
-			// Create new variant by adding current pack to existing combination
-			newVariant := &variant{
-				numberOfPacks: current.numberOfPacks + 1,
-				overshoot:     newSum - amount,
-				combination:   make(map[int64]int64, len(current.combination)),
-			}
+// calculateBounded finds combinations of normalized packs picked by the selector
+// which don't use more packs than available in stock and follow the rules.
+func calculateBounded(
+	w window,
+	config tableConfig,
+	stock map[int64]int64,
+	rules map[int64]Rule,
+	input InputLimits,
+	selector selectVariants,
+	stop *checkpoint,
+) ([]map[int64]int64, error) {
+	if config.maxPacks > 0 {
+		return nil, fmt.Errorf("%w: maximum number of packs with stock or rules under cost strategies", ErrUnsupportedOptions)
+	}
 
-			maps.Copy(newVariant.combination, current.combination)
-			newVariant.combination[pack]++
+	items, largest, capacity := stockItems(config, stock, rules, w.target)
+	if capacity < w.from {
+		if len(stock) == 0 {
+			return nil, ErrNoCombination
+		}
 
-			// Keep the better variant for this sum
-			if variants[newSum] == nil {
-				variants[newSum] = newVariant
-			} else if isBetter(newVariant, variants[newSum]) {
-				variants[newSum] = newVariant
-			}
+		return nil, ErrInsufficientStock
+	}
+
+	var maxRange = min(saturatedAdd(w.target, largest), capacity)
+	if err := input.checkMemory(maxRange, boundedBytesPerSum(items)); err != nil {
+		return nil, err
+	}
+
+	table, err := newBoundedTable(items, config.criteria, maxRange, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any sum of available packs can be reduced into the window by dropping packs
+	// of a size or a step of them, so only the strategy can reject all of them
+	var variants = selector(w, maxRange, 0, table)
+	if len(variants) == 0 {
+		return nil, ErrNoCombination
+	}
+
+	var combinations = make([]map[int64]int64, len(variants))
+	for i, variant := range variants {
+		combinations[i] = table.combination(variant.sum)
+	}
+
+	return combinations, nil
+}
+
+// normalizePacks prepares pack sizes for calculation: non-positive sizes and duplicates
+// are stripped and the rest are sorted and divided by their greatest common divisor,
+// which shrinks the table by that factor. The divisor is returned to scale results back.
+func normalizePacks(packs []int64) ([]int64, int64) {
+	var normalized = make([]int64, 0, len(packs))
+
+	for _, pack := range packs {
+		if pack > 0 {
+			normalized = append(normalized, pack)
+		}
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	var divisor int64
+	for _, pack := range normalized {
+		divisor = gcd(divisor, pack)
+	}
+
+	if divisor > 1 {
+		for i := range normalized {
+			normalized[i] /= divisor
+		}
+	}
+
+	return normalized, max(divisor, 1)
+}
+
+// normalizeCosts returns costs of normalized packs by their index.
+func normalizeCosts(costs map[int64]int64, packs []int64, divisor int64) []int64 {
+	var normalized = make([]int64, len(packs))
+
+	for i, pack := range packs {
+		normalized[i] = costs[pack*divisor]
+	}
+
+	return normalized
+}
+
+// denormalize scales pack sizes of the combination back by the divisor.
+func denormalize(combination map[int64]int64, divisor int64) map[int64]int64 {
+	if divisor == 1 {
+		return combination
+	}
+
+	var result = make(map[int64]int64, len(combination))
+	for pack, count := range combination {
+		result[pack*divisor] = count
+	}
+
+	return result
+}
+
+// gcd returns the greatest common divisor of two non-negative numbers.
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// periodicityThreshold returns the sum above which every optimal combination contains
+// the largest pack L. Any L smaller packs contain a subset adding up to a multiple of L,
+// which can be replaced by fewer largest packs, so an optimal combination holds less than L
+// smaller packs and sums beyond (L-1) * second largest pack can't be made without L.
+// Returns false if the threshold doesn't fit into int64.
+func periodicityThreshold(packs []int64) (int64, bool) {
+	var (
+		largest = packs[len(packs)-1]
+		second  int64
+	)
+
+	for i := len(packs) - 1; i >= 0; i-- {
+		if packs[i] < largest {
+			second = packs[i]
+			break
 		}
 	}
 
-	var result = getOptimalVariant(amount, maxRange, variants)
-	if result == nil {
-		return nil, errors.New("could not find a valid combination")
+	if second > 0 && largest-1 > (math.MaxInt64-largest)/second {
+		return 0, false
 	}
 
-	return result.combination, nil
+	return (largest - 1) * second, true
 }
 
-func getOptimalVariant(amount, maxRange int64, variants []*variant) *variant {
-	var result *variant
-	for s := amount; s <= maxRange; s++ {
-		if variants[s] == nil {
+// bulkPacks returns how many largest packs can be set aside before calculation.
+// Above the periodicity threshold optimal combination for a sum is the optimal one for
+// the sum reduced by the largest pack plus that pack, so the amount is reduced until
+// the whole search window starting from the amount stays above the threshold.
+func bulkPacks(amount int64, packs []int64) int64 {
+	threshold, ok := periodicityThreshold(packs)
+	if !ok || amount <= threshold+1 {
+		return 0
+	}
+
+	return (amount - threshold - 1) / packs[len(packs)-1]
+}
+
+// rankVariants returns up to limit best variants accepted by the strategy among sums of
+// the window up to maxRange, from the best one. Among equally good variants the one closest
+// to the amount goes first. Bulk packs taken analytically are added to scores. Sums beyond
+// the window target plus the largest pack aren't worth checking: dropping any pack from such
+// a combination still covers the target with less overshoot, fewer packs and lower cost.
+func rankVariants(strategy Strategy, w window, maxRange, bulk int64, solution solution, limit int) []*variant {
+	var (
+		criteria = strategy.Criteria()
+		result   = make([]*variant, 0, limit+1)
+	)
+
+	for s := w.from; s <= maxRange; s++ {
+		var current = solution.variant(w, s)
+		if current == nil {
+			continue
+		}
+
+		current.Packs += bulk
+
+		if !strategy.Accept(current.Score) {
 			continue
 		}
-		if result == nil || isBetter(variants[s], result) {
-			result = variants[s]
+
+		// Insert the variant keeping the result sorted and cut to the limit
+		var i = len(result)
+		for i > 0 && isPreferred(criteria, w, current, result[i-1]) {
+			i--
+		}
+
+		if i < limit {
+			result = slices.Insert(result, i, current)[:min(len(result)+1, limit)]
 		}
 	}
 
 	return result
 }
 
-func isBetter(left, right *variant) bool {
-	if left.overshoot < right.overshoot {
-		return true
-	} else if left.overshoot > right.overshoot {
-		return false
+// isPreferred reports whether the left variant goes before the right one: it's better
+// by criteria or equally good and closer to the amount.
+func isPreferred(criteria []Criterion, w window, left, right *variant) bool {
+	return isBetter(criteria, left, right) ||
+		!isBetter(criteria, right, left) && w.isCloser(left.sum, right.sum)
+}
+
+// isBetter reports whether the left variant is preferred over the right one by criteria.
+func isBetter(criteria []Criterion, left, right *variant) bool {
+	for _, criterion := range criteria {
+		if l, r := left.Value(criterion), right.Value(criterion); l != r {
+			return l < r
+		}
+	}
+
+	return false
+}
+
+// isBetterSum reports whether a combination with count packs and the cost is preferred
+// over the current one reaching the same sum. Overshoot is equal for the same sum,
+// so only packs and cost are compared.
+func isBetterSum(criteria []Criterion, count, currentCount uint32, cost, currentCost int64) bool {
+	for _, criterion := range criteria {
+		switch criterion {
+		case CriterionPacks:
+			if count != currentCount {
+				return count < currentCount
+			}
+		case CriterionCost:
+			if cost != currentCost {
+				return cost < currentCost
+			}
+		}
 	}
 
-	return left.numberOfPacks < right.numberOfPacks
+	return false
 }
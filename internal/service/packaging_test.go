@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"math"
+	"math/rand"
+	"slices"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,6 +134,226 @@ func TestNumberOfPacks_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestNumberOfPacks_MatchesReference(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		random  = rand.New(rand.NewSource(42))
+		configs = [][]int64{
+			{250, 500, 1000, 2000, 5000},
+			{23, 31, 53},
+			{7, 11, 13, 17},
+			{3, 5},
+			{100, 100, 200},
+			{6, 9, 20},
+		}
+	)
+
+	for _, packs := range configs {
+		for range 50 {
+			var amount = random.Int63n(600) + 1
+
+			expected, found := bruteForce(amount, packs, constraints{})
+			require.True(t, found)
+
+			actual, err := NumberOfPacks(ctx, amount, packs)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, scoreOf(amount, actual, nil), "packs %v, amount %d: %v", packs, amount, actual)
+		}
+	}
+}
+
+func TestNumberOfPacks_DoesNotModifyPacks(t *testing.T) {
+	packs := []int64{5000, 250, 1000}
+
+	_, err := NumberOfPacks(context.Background(), 1001, packs)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5000, 250, 1000}, packs)
+}
+
+func TestNumberOfPacks_HugeAmount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping huge amount calculation in short mode")
+	}
+
+	packs := []int64{23, 31, 53}
+	result, err := NumberOfPacks(context.Background(), 10_000_001, packs)
+
+	require.NoError(t, err)
+
+	total := int64(0)
+	for packSize, count := range result {
+		total += packSize * count
+	}
+	assert.Equal(t, int64(10_000_001), total, "amount is representable exactly")
+}
+
+func TestNumberOfPacks_ClosedForm(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("matches reference around threshold", func(t *testing.T) {
+		for _, packs := range [][]int64{{3, 5}, {6, 9, 20}, {23, 31, 53}, {40, 40, 70}} {
+			threshold, ok := periodicityThreshold(packs)
+			require.True(t, ok)
+
+			largest := packs[len(packs)-1]
+			for amount := max(threshold-largest, 1); amount <= threshold+3*largest; amount++ {
+				expected, found := bruteForce(amount, packs, constraints{})
+				require.True(t, found)
+
+				actual, err := NumberOfPacks(ctx, amount, packs)
+
+				require.NoError(t, err)
+				require.Equal(t, expected, scoreOf(amount, actual, nil), "packs %v, amount %d: %v", packs, amount, actual)
+			}
+		}
+	})
+
+	t.Run("trillion items", func(t *testing.T) {
+		packs := []int64{250, 500, 1000, 2000, 5000}
+		result, err := NumberOfPacks(ctx, 1_000_000_000_001, packs)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{5000: 200_000_000, 250: 1}, result)
+	})
+
+	t.Run("single pack size", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1_000_000_000_000, []int64{7})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{7: 142_857_142_858}, result)
+	})
+}
+
+func TestNormalizePacks(t *testing.T) {
+	tests := []struct {
+		name            string
+		packs           []int64
+		expected        []int64
+		expectedDivisor int64
+	}{
+		{
+			name:            "common divisor",
+			packs:           []int64{5000, 250, 1000, 2000, 500},
+			expected:        []int64{1, 2, 4, 8, 20},
+			expectedDivisor: 250,
+		},
+		{
+			name:            "coprime sizes",
+			packs:           []int64{7, 3},
+			expected:        []int64{3, 7},
+			expectedDivisor: 1,
+		},
+		{
+			name:            "zero and negative sizes",
+			packs:           []int64{0, -250, 250, 500},
+			expected:        []int64{1, 2},
+			expectedDivisor: 250,
+		},
+		{
+			name:            "duplicates",
+			packs:           []int64{250, 250, 750},
+			expected:        []int64{1, 3},
+			expectedDivisor: 250,
+		},
+		{
+			name:            "no valid sizes",
+			packs:           []int64{0, -1},
+			expected:        []int64{},
+			expectedDivisor: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, divisor := normalizePacks(tt.packs)
+
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.expectedDivisor, divisor)
+		})
+	}
+}
+
+func TestNumberOfPacks_Normalization(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("zero size is ignored", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 251, []int64{0, 250})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 2}, result)
+	})
+
+	t.Run("negative size is ignored", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 600, []int64{-100, 250, 500})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{500: 1, 250: 1}, result)
+	})
+
+	t.Run("only invalid sizes", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 100, []int64{0, -5})
+
+		assert.ErrorIs(t, err, ErrInvalidPackSize)
+		assert.Nil(t, result)
+	})
+
+	t.Run("duplicate sizes", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 350, []int64{100, 100, 200})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{200: 2}, result)
+	})
+
+	t.Run("huge divisor", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 5, []int64{math.MaxInt64})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{math.MaxInt64: 1}, result)
+	})
+}
+
+func TestBulkPacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		packs    []int64
+		amount   int64
+		expected int64
+	}{
+		{
+			name:     "below threshold",
+			packs:    []int64{3, 5},
+			amount:   13,
+			expected: 0,
+		},
+		{
+			name:     "above threshold",
+			packs:    []int64{3, 5},
+			amount:   100,
+			expected: 17, // threshold is 12, residual 15 keeps window above it
+		},
+		{
+			name:     "single pack size",
+			packs:    []int64{10},
+			amount:   101,
+			expected: 10,
+		},
+		{
+			name:     "threshold overflow",
+			packs:    []int64{1 << 40, 1 << 41},
+			amount:   1 << 62,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, bulkPacks(tt.amount, tt.packs))
+		})
+	}
+}
+
 func TestNumberOfPacks_DifferentPackSizes(t *testing.T) {
 	ctx := context.Background()
 
@@ -189,14 +413,184 @@ func TestNumberOfPacks_ContextHandling(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		// The current implementation doesn't check context cancellation
-		// but we test the interface
 		result, err := NumberOfPacks(ctx, 1000, packs)
 
-		// Current implementation doesn't handle context cancellation
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+	})
+
+	t.Run("canceled during calculation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			stop  = newCheckpoint(ctx)
+			steps int
+		)
+
+		// The table is filled until the checkpoint notices cancellation
+		for steps = 1; stop.step() == nil; steps++ {
+			if steps == checkPeriod/2 {
+				cancel()
+			}
+		}
+
+		assert.Equal(t, checkPeriod, steps)
+
+		var timeout *TimeoutError
+		require.ErrorAs(t, stop.err(), &timeout)
+		assert.Equal(t, context.Canceled, timeout.Cause)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		_, err := NumberOfPacks(ctx, 1000, packs)
+
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("budget exceeded", func(t *testing.T) {
+		// Cost strategies don't fill the bulk of the amount analytically
+		var opts = []Option{WithStrategy(Cheapest()), WithBudget(time.Millisecond)}
+
+		_, err := NumberOfPacks(context.Background(), 20_000_000, []int64{23, 31, 53}, opts...)
+
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("budget not exceeded", func(t *testing.T) {
+		result, err := NumberOfPacks(context.Background(), 1000, packs, WithBudget(time.Minute))
+
 		require.NoError(t, err)
 		assert.NotEmpty(t, result)
 	})
+
+	t.Run("stopped table isn't cached", func(t *testing.T) {
+		var (
+			cache  = NewTableCache(1 << 30)
+			config = defaultConfig(23, 31, 53)
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := cache.table(config, 100, nil)
+		require.NoError(t, err)
+
+		_, err = cache.table(config, 100_000, newCheckpoint(ctx))
+		require.ErrorIs(t, err, ErrTimeout)
+
+		table, err := cache.table(config, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), table.maxRange(), "cached table must stay as it was")
+	})
+}
+
+// constraints restrict combinations enumerated by bruteForce, zero fields don't restrict them.
+type constraints struct {
+	strategy Strategy        // Strategy ranking combinations, LeastOvershoot if nil
+	rules    map[int64]Rule  // Quantity rules by size
+	stock    map[int64]int64 // Available packs by size, absent sizes are unlimited
+	costs    map[int64]int64 // Unit cost of a pack by size
+	under    Tolerance       // Allowed shortfall
+	over     Tolerance       // Allowed excess not counted as overshoot
+}
+
+// bruteForce enumerates every combination of packs covering the amount within constraints
+// and returns the score of the best one by the strategy. It's the reference every optimized
+// calculation is verified against.
+func bruteForce(amount int64, packs []int64, c constraints) (Score, bool) {
+	var strategy = c.strategy
+	if strategy == nil {
+		strategy = LeastOvershoot()
+	}
+
+	var (
+		lowest  = max(amount-c.under.items(amount), 1)
+		highest = amount + c.over.items(amount)
+	)
+
+	packs = slices.Compact(slices.Sorted(slices.Values(packs)))
+
+	var (
+		best        *variant
+		combination = make(map[int64]int64, len(packs))
+		walk        func(i int, shipped int64)
+	)
+
+	walk = func(i int, shipped int64) {
+		if i == len(packs) {
+			if shipped < lowest {
+				return
+			}
+
+			var current = &variant{Score: toleratedScoreOf(highest, combination, c.costs)}
+			if strategy.Accept(current.Score) && (best == nil || isBetter(strategy.Criteria(), current, best)) {
+				best = current
+			}
+
+			return
+		}
+
+		var (
+			pack = packs[i]
+			rule = c.rules[pack]
+			// Dropping a step of packs beyond the amount leaves a better combination
+			limit = max((highest-shipped)/pack+1, rule.Min) + rule.step()
+		)
+
+		if available, limited := c.stock[pack]; limited {
+			limit = min(limit, available)
+		}
+
+		for count := int64(0); count <= limit; count++ {
+			if allows(rule, count) {
+				combination[pack] = count
+				walk(i+1, shipped+count*pack)
+			}
+		}
+
+		delete(combination, pack)
+	}
+
+	walk(0, 0)
+
+	if best == nil {
+		return Score{}, false
+	}
+
+	return best.Score, true
+}
+
+// allows reports whether the number of packs fits the rule.
+func allows(rule Rule, count int64) bool {
+	return count == 0 ||
+		count >= rule.Min && (rule.Max == 0 || count <= rule.Max) && (rule.Step == 0 || count%rule.Step == 0)
+}
+
+// scoreOf measures the combination covering the amount.
+func scoreOf(amount int64, combination map[int64]int64, costs map[int64]int64) Score {
+	var score = Score{Overshoot: -amount}
+	for pack, count := range combination {
+		score.Overshoot += pack * count
+		score.Packs += count
+		score.Cost += costs[pack] * count
+	}
+
+	return score
+}
+
+// toleratedScoreOf measures the combination counting only items above highest as overshoot.
+func toleratedScoreOf(highest int64, combination map[int64]int64, costs map[int64]int64) Score {
+	var score = scoreOf(highest, combination, costs)
+	score.Overshoot = max(score.Overshoot, 0)
+
+	return score
 }
 
 // Benchmark tests
@@ -218,6 +612,15 @@ func BenchmarkNumberOfPacks_Large(b *testing.B) {
 	}
 }
 
+func BenchmarkNumberOfPacks_Huge(b *testing.B) {
+	ctx := context.Background()
+	packs := []int64{23, 31, 53}
+
+	for b.Loop() {
+		NumberOfPacks(ctx, 10_000_000, packs)
+	}
+}
+
 func BenchmarkNumberOfPacks_Manypacks(b *testing.B) {
 	ctx := context.Background()
 
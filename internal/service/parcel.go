@@ -0,0 +1,97 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Parcel is a group of consecutive identical parcels of packs.
+type Parcel struct {
+	Packs map[int64]int64 // Number of packs by size in every parcel
+	First int64           // One-based number of the first parcel of the group
+	Count int64           // Number of identical parcels
+}
+
+// SplitParcels splits the combination of packs into the fewest parcels of at most maxPacks
+// packs balanced as evenly as possible: numbers of packs in parcels differ by one at most and
+// packs are dealt to parcels in turn from the largest size, so every size is spread evenly too.
+// Identical consecutive parcels are grouped, so splitting doesn't depend on the number of packs.
+func SplitParcels(combination map[int64]int64, maxPacks int64) ([]Parcel, error) {
+	if maxPacks <= 0 {
+		return nil, fmt.Errorf("maximum packs per parcel must be positive: %d", maxPacks)
+	}
+
+	var (
+		sizes = slices.SortedFunc(maps.Keys(combination), func(a, b int64) int { return cmp.Compare(b, a) })
+		total int64
+	)
+
+	for _, size := range sizes {
+		if combination[size] < 0 {
+			return nil, fmt.Errorf("number of packs %d must not be negative: %d", size, combination[size])
+		}
+
+		total += combination[size]
+	}
+
+	if total == 0 {
+		return []Parcel{}, nil
+	}
+
+	// Packs lined up from the largest size are dealt to parcels in turn, so the contents
+	// of a parcel change only where some size starts or ends in the line
+	var (
+		parcels = (total + maxPacks - 1) / maxPacks
+		bounds  = []int64{0, parcels}
+		starts  = make(map[int64]int64, len(sizes))
+		start   int64
+	)
+
+	for _, size := range sizes {
+		starts[size] = start
+		start += combination[size]
+
+		bounds = append(bounds, starts[size]%parcels, start%parcels)
+	}
+
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	var groups []Parcel
+	for i, first := range bounds[:len(bounds)-1] {
+		var parcel = Parcel{
+			Packs: make(map[int64]int64),
+			First: first + 1,
+			Count: bounds[i+1] - first,
+		}
+
+		for _, size := range sizes {
+			var count = dealt(starts[size]+combination[size], first, parcels) - dealt(starts[size], first, parcels)
+			if count > 0 {
+				parcel.Packs[size] = count
+			}
+		}
+
+		// Merge with the previous group of identical parcels
+		if last := len(groups) - 1; last >= 0 && maps.Equal(groups[last].Packs, parcel.Packs) {
+			groups[last].Count += parcel.Count
+			continue
+		}
+
+		groups = append(groups, parcel)
+	}
+
+	return groups, nil
+}
+
+// dealt is the number of the first n packs of the line dealt to the zero-based parcel
+// when packs are dealt to the parcels in turn.
+func dealt(n, parcel, parcels int64) int64 {
+	if n <= parcel {
+		return 0
+	}
+
+	return (n-parcel-1)/parcels + 1
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitParcels(t *testing.T) {
+	t.Run("balanced parcels", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{250: 1, 1000: 12}, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Parcel{
+			{Packs: map[int64]int64{250: 1, 1000: 4}, First: 1, Count: 1},
+			{Packs: map[int64]int64{1000: 4}, First: 2, Count: 2},
+		}, parcels)
+	})
+
+	t.Run("sizes spread evenly", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{250: 2, 500: 2, 1000: 2}, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Parcel{
+			{Packs: map[int64]int64{250: 1, 500: 1, 1000: 1}, First: 1, Count: 2},
+		}, parcels)
+	})
+
+	t.Run("single parcel", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{250: 1, 1000: 1}, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Parcel{
+			{Packs: map[int64]int64{250: 1, 1000: 1}, First: 1, Count: 1},
+		}, parcels)
+	})
+
+	t.Run("huge number of packs", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{250: 1, 5000: 200_000_000}, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Parcel{
+			{Packs: map[int64]int64{5000: 3}, First: 1, Count: 66_666_666},
+			{Packs: map[int64]int64{250: 1, 5000: 2}, First: 66_666_667, Count: 1},
+		}, parcels)
+	})
+
+	t.Run("empty combination", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{}, 3)
+
+		require.NoError(t, err)
+		assert.Empty(t, parcels)
+	})
+
+	t.Run("invalid maximum", func(t *testing.T) {
+		parcels, err := SplitParcels(map[int64]int64{250: 1}, 0)
+
+		assert.Error(t, err)
+		assert.Nil(t, parcels)
+	})
+}
+
+func TestSplitParcels_Balanced(t *testing.T) {
+	var random = rand.New(rand.NewSource(11))
+
+	for range 200 {
+		var (
+			maxPacks    = random.Int63n(10) + 1
+			combination = map[int64]int64{4: random.Int63n(50), 6: random.Int63n(50), 9: random.Int63n(50)}
+			packs       = make(map[int64]int64)
+			total       int64
+			number      int64 = 1
+			smallest    int64 = -1
+			largest     int64
+		)
+
+		for _, count := range combination {
+			total += count
+		}
+
+		parcels, err := SplitParcels(combination, maxPacks)
+		require.NoError(t, err)
+
+		for _, parcel := range parcels {
+			assert.Equal(t, number, parcel.First)
+			assert.Positive(t, parcel.Count)
+
+			var size int64
+			for pack, count := range parcel.Packs {
+				size += count
+				packs[pack] += count * parcel.Count
+			}
+
+			assert.LessOrEqual(t, size, maxPacks)
+
+			if smallest < 0 || size < smallest {
+				smallest = size
+			}
+
+			largest = max(largest, size)
+			number += parcel.Count
+		}
+
+		assert.Equal(t, (total+maxPacks-1)/maxPacks, number-1, "parcels of %v by %d", combination, maxPacks)
+
+		if total > 0 {
+			assert.LessOrEqual(t, largest-smallest, int64(1), "parcels of %v by %d", combination, maxPacks)
+		}
+
+		for size, count := range combination {
+			assert.Equal(t, count, packs[size], "size %d of %v", size, combination)
+		}
+	}
+}
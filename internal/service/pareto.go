@@ -0,0 +1,69 @@
+package service
+
+import "context"
+
+// ParetoFront calculates all combinations of packs covering the amount which aren't dominated
+// by overshoot and number of packs: every other combination ships more items or uses more packs.
+// The front is ordered by overshoot from the least one, so the number of packs decreases.
+// The strategy only bounds the front by accepted combinations, e.g. WithinPacks.
+func ParetoFront(
+	ctx context.Context,
+	amount int64,
+	packs []int64,
+	opts ...Option,
+) ([]Candidate, error) {
+	var options = newOptions(opts)
+
+	// Fewest packs are kept for every sum, so every sum has its only candidate
+	combinations, err := findCombinations(ctx, amount, packs, []Criterion{CriterionOvershoot, CriterionPacks}, options,
+		func(w window, maxRange, bulk int64, solution solution) []*variant {
+			return paretoVariants(options.strategy, w, maxRange, bulk, solution)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var front = make([]Candidate, len(combinations))
+	for i, combination := range combinations {
+		front[i] = newCandidate(amount, combination, options.costs)
+	}
+
+	return front, nil
+}
+
+// paretoVariants returns variants accepted by the strategy among sums of the window up to
+// maxRange which aren't dominated by overshoot and number of packs, ordered by overshoot.
+// Overshoot doesn't decrease with the sum, so a variant joins the front if it has fewer packs
+// than the last one. Among variants of equal overshoot and packs the one closest to the amount wins.
+func paretoVariants(strategy Strategy, w window, maxRange, bulk int64, solution solution) []*variant {
+	var front []*variant
+
+	for s := w.from; s <= maxRange; s++ {
+		var current = solution.variant(w, s)
+		if current == nil {
+			continue
+		}
+
+		current.Packs += bulk
+
+		if !strategy.Accept(current.Score) {
+			continue
+		}
+
+		var last = len(front) - 1
+		switch {
+		case last < 0:
+			front = append(front, current)
+		case current.Overshoot == front[last].Overshoot:
+			if current.Packs < front[last].Packs ||
+				current.Packs == front[last].Packs && w.isCloser(current.sum, front[last].sum) {
+				front[last] = current
+			}
+		case current.Packs < front[last].Packs:
+			front = append(front, current)
+		}
+	}
+
+	return front
+}
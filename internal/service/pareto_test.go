@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParetoFront(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trade-offs", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 25, []int64{1, 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{1: 5, 10: 2}, Shipped: 25, Overshoot: 0, Count: 7},
+			{Packs: map[int64]int64{10: 3}, Shipped: 30, Overshoot: 5, Count: 3},
+		}, front)
+	})
+
+	t.Run("single optimum", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 1001, []int64{250, 500, 1000})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{250: 1, 1000: 1}, Shipped: 1250, Overshoot: 249, Count: 2},
+		}, front)
+	})
+
+	t.Run("costs", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 25, []int64{1, 10}, WithCosts(map[int64]int64{1: 1, 10: 4}))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{13, 12}, []int64{front[0].Cost, front[1].Cost})
+	})
+
+	t.Run("bounded by strategy", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 25, []int64{1, 10}, WithStrategy(WithinPacks(5)))
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{10: 3}, Shipped: 30, Overshoot: 5, Count: 3},
+		}, front)
+	})
+
+	t.Run("tolerance", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 25, []int64{1, 10}, WithTolerance(Tolerance{Items: 5}, Tolerance{}))
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{10: 2}, Shipped: 20, Overshoot: -5, Count: 2},
+		}, front)
+	})
+
+	t.Run("stock", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 25, []int64{1, 10}, WithStock(map[int64]int64{10: 1}))
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{
+			{Packs: map[int64]int64{1: 15, 10: 1}, Shipped: 25, Overshoot: 0, Count: 16},
+		}, front)
+	})
+
+	t.Run("zero amount", func(t *testing.T) {
+		front, err := ParetoFront(ctx, 0, []int64{1, 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Candidate{{Packs: map[int64]int64{}}}, front)
+	})
+}
+
+func TestParetoFront_MatchesReference(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = rand.New(rand.NewSource(13))
+		packs  = []int64{4, 6, 9}
+	)
+
+	for range 100 {
+		var amount = random.Int63n(100) + 1
+
+		front, err := ParetoFront(ctx, amount, packs)
+		require.NoError(t, err)
+
+		// Fewest packs of every sum up to the amount plus the largest pack
+		var fewest = make([]int64, amount+10)
+		for sum := int64(1); sum < int64(len(fewest)); sum++ {
+			fewest[sum] = -1
+			for _, pack := range packs {
+				if sum >= pack && fewest[sum-pack] >= 0 && (fewest[sum] < 0 || fewest[sum-pack]+1 < fewest[sum]) {
+					fewest[sum] = fewest[sum-pack] + 1
+				}
+			}
+		}
+
+		var expected [][2]int64
+		for sum := amount; sum < int64(len(fewest)); sum++ {
+			if fewest[sum] < 0 {
+				continue
+			}
+
+			if len(expected) == 0 || fewest[sum] < expected[len(expected)-1][1] {
+				expected = append(expected, [2]int64{sum - amount, fewest[sum]})
+			}
+		}
+
+		var actual [][2]int64
+		for _, candidate := range front {
+			actual = append(actual, [2]int64{candidate.Overshoot, candidate.Count})
+		}
+
+		assert.Equal(t, expected, actual, "amount %d", amount)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+)
+
+// Rule restricts the number of packs of a size in a combination. Unless the size
+// isn't used at all, its number of packs is at least Min, at most Max and a multiple of Step.
+// Zero fields don't restrict the number.
+type Rule struct {
+	Min  int64 // Fewest packs of the size if it's used, e.g. a minimum order
+	Max  int64 // Most packs of the size
+	Step int64 // Number of packs of the size must be its multiple, e.g. full pallet layers
+}
+
+// WithRules restricts numbers of packs by size. Sizes absent from rules are unrestricted.
+func WithRules(rules map[int64]Rule) Option {
+	return func(o *options) {
+		o.rules = rules
+	}
+}
+
+// NewRule converts the quantity rule DTO into a rule of the calculation.
+func NewRule(rule model.QuantityRule) Rule {
+	return Rule{Min: rule.MinCount, Max: rule.MaxCount, Step: rule.Step}
+}
+
+// PackRules returns quantity rules of packs of the configuration by size, nil if no pack has any.
+func PackRules(pack *model.Pack) map[int64]Rule {
+	var rules map[int64]Rule
+	for _, item := range pack.PackItems {
+		var rule = NewRule(model.QuantityRule{MinCount: item.MinCount, MaxCount: item.MaxCount, Step: item.Step})
+		if rule == (Rule{}) {
+			continue
+		}
+
+		if rules == nil {
+			rules = make(map[int64]Rule)
+		}
+
+		rules[item.Size] = rule
+	}
+
+	return rules
+}
+
+// Validate checks that the rule has no negative fields and some positive number of packs fits it.
+func (r Rule) Validate() error {
+	switch {
+	case r.Min < 0 || r.Max < 0 || r.Step < 0:
+		return fmt.Errorf("rule fields must not be negative: %+v", r)
+	case r.Max > 0 && r.steps() < r.leastSteps():
+		return fmt.Errorf("no number of packs fits the rule: %+v", r)
+	}
+
+	return nil
+}
+
+// step returns the number of packs the size is taken by.
+func (r Rule) step() int64 {
+	return max(r.Step, 1)
+}
+
+// leastSteps returns the fewest steps of the size if it's used.
+func (r Rule) leastSteps() int64 {
+	return (max(r.Min, 1)-1)/r.step() + 1
+}
+
+// steps returns the most steps of the size, -1 if unlimited.
+func (r Rule) steps() int64 {
+	if r.Max == 0 {
+		return -1
+	}
+
+	return r.Max / r.step()
+}
+
+// normalizeRules converts rules keyed by pack sizes into rules keyed by normalized sizes.
+func normalizeRules(rules map[int64]Rule, packs []int64, divisor int64) map[int64]Rule {
+	var normalized = make(map[int64]Rule, len(rules))
+
+	for pack, rule := range rules {
+		if pack%divisor == 0 && slices.Contains(packs, pack/divisor) {
+			normalized[pack/divisor] = rule
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacks_WithRules(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		packs = []int64{250, 500, 1000}
+	)
+
+	t.Run("step multiple", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1001, []int64{250, 1000}, WithRules(map[int64]Rule{1000: {Step: 4}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 5}, result)
+
+		result, err = NumberOfPacks(ctx, 3900, []int64{250, 1000}, WithRules(map[int64]Rule{1000: {Step: 4}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1000: 4}, result)
+	})
+
+	t.Run("minimum count", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1250, packs, WithRules(map[int64]Rule{250: {Min: 3}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 3, 500: 1}, result)
+
+		result, err = NumberOfPacks(ctx, 501, packs, WithRules(map[int64]Rule{250: {Min: 3}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 3}, result)
+	})
+
+	t.Run("maximum count", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 3000, packs, WithRules(map[int64]Rule{1000: {Max: 2}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{500: 2, 1000: 2}, result)
+	})
+
+	t.Run("all rules", func(t *testing.T) {
+		var rules = map[int64]Rule{250: {Min: 2, Max: 6, Step: 2}, 1000: {Max: 1}}
+
+		result, err := NumberOfPacks(ctx, 2100, []int64{250, 1000}, WithRules(rules))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 6, 1000: 1}, result)
+
+		_, err = NumberOfPacks(ctx, 2600, []int64{250, 1000}, WithRules(rules))
+		assert.ErrorIs(t, err, ErrNoCombination)
+	})
+
+	t.Run("with stock", func(t *testing.T) {
+		var (
+			rules = map[int64]Rule{1000: {Step: 2}}
+			stock = map[int64]int64{1000: 3}
+		)
+
+		result, err := NumberOfPacks(ctx, 3500, packs, WithRules(rules), WithStock(stock))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{500: 3, 1000: 2}, result)
+	})
+
+	t.Run("normalized sizes", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 45, []int64{10, 20}, WithRules(map[int64]Rule{10: {Min: 3}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{10: 3, 20: 1}, result)
+	})
+
+	t.Run("huge counts", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 100, []int64{5, 7}, WithRules(map[int64]Rule{5: {Max: 9e18}}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{5: 6, 7: 10}, result)
+
+		for _, rule := range []Rule{{Min: 9e18}, {Step: 9e18}} {
+			result, err = NumberOfPacks(ctx, 100, []int64{5, 7}, WithRules(map[int64]Rule{5: rule}))
+
+			require.NoError(t, err, "rule %+v", rule)
+			assert.Equal(t, map[int64]int64{7: 15}, result, "rule %+v", rule)
+		}
+
+		_, err = NumberOfPacks(ctx, 100, []int64{1, 7}, WithRules(map[int64]Rule{1: {Min: 9e18}}))
+		assert.ErrorIs(t, err, ErrAmountTooLarge)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		for _, rule := range []Rule{{Min: -1}, {Max: -1}, {Step: -1}, {Min: 5, Max: 4}, {Max: 3, Step: 4}} {
+			_, err := NumberOfPacks(ctx, 1000, packs, WithRules(map[int64]Rule{250: rule}))
+
+			assert.Error(t, err, "rule %+v", rule)
+		}
+	})
+}
+
+func TestPackRules(t *testing.T) {
+	assert.Equal(t, Rule{Min: 2, Max: 8, Step: 2}, NewRule(model.QuantityRule{MinCount: 2, MaxCount: 8, Step: 2}))
+
+	pack := &model.Pack{PackItems: []model.PackItem{
+		{Size: 250},
+		{Size: 500, MinCount: 2, MaxCount: 8, Step: 2},
+	}}
+
+	assert.Equal(t, map[int64]Rule{500: {Min: 2, Max: 8, Step: 2}}, PackRules(pack))
+	assert.Nil(t, PackRules(&model.Pack{PackItems: []model.PackItem{{Size: 250}}}))
+}
+
+func TestNumberOfPacks_WithRulesMatchesReference(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = rand.New(rand.NewSource(18))
+		packs  = []int64{3, 5, 8}
+	)
+
+	for range 300 {
+		var (
+			amount = random.Int63n(60) + 1
+			rules  = make(map[int64]Rule)
+			stock  = make(map[int64]int64)
+			costs  = make(map[int64]int64)
+		)
+
+		for _, pack := range packs {
+			var rule = Rule{Min: random.Int63n(4), Step: random.Int63n(3)}
+			if random.Intn(2) == 0 {
+				rule.Max = rule.Min + random.Int63n(8) + rule.Step
+			}
+
+			rules[pack] = rule
+			costs[pack] = random.Int63n(5)
+
+			if random.Intn(3) == 0 {
+				stock[pack] = random.Int63n(10)
+			}
+		}
+
+		for _, strategy := range []Strategy{LeastOvershoot(), FewestPacks(), Cheapest()} {
+			var opts = []Option{WithRules(rules), WithStock(stock), WithCosts(costs), WithStrategy(strategy)}
+
+			result, err := NumberOfPacks(ctx, amount, packs, opts...)
+
+			var expected, found = bruteForce(amount, packs, constraints{strategy: strategy, rules: rules, stock: stock, costs: costs})
+			if !found {
+				assert.Error(t, err, "amount %d, rules %v, stock %v", amount, rules, stock)
+				continue
+			}
+
+			require.NoError(t, err, "amount %d, rules %v, stock %v", amount, rules, stock)
+
+			var score = scoreOf(amount, result, costs)
+			assert.Equal(t, expected, score, "%s: amount %d, rules %v, stock %v: %v",
+				strategy.Name(), amount, rules, stock, result)
+
+			for pack, count := range result {
+				assert.True(t, allows(rules[pack], count), "pack %d, count %d, rule %+v", pack, count, rules[pack])
+			}
+		}
+	}
+}
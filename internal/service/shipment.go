@@ -0,0 +1,171 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ErrPackOverLimits is returned when a single pack doesn't fit the limits of a shipment.
+var ErrPackOverLimits = errors.New("pack exceeds shipment limits")
+
+// Limits bound the total weight and volume of packs in a single shipment, zero means no limit.
+type Limits struct {
+	Weight int64 // Largest total weight of a shipment
+	Volume int64 // Largest total volume of a shipment
+}
+
+// Measures are the weight and volume of a single pack.
+type Measures struct {
+	Weight int64 // Weight of the pack
+	Volume int64 // Volume of the pack
+}
+
+// Shipment is a group of identical shipments of packs.
+type Shipment struct {
+	Packs  map[int64]int64 // Number of packs by size in every shipment
+	Weight int64           // Total weight of every shipment
+	Volume int64           // Total volume of every shipment
+	Count  int64           // Number of identical shipments
+}
+
+// SplitShipments splits the combination of packs into shipments within the limits
+// by first fit decreasing: sizes heaviest relative to the limits go first and every pack
+// goes into the first shipment it fits. Packs without measures weigh nothing.
+// Identical shipments are grouped, so splitting doesn't depend on the number of packs.
+func SplitShipments(combination map[int64]int64, measures map[int64]Measures, limits Limits) ([]Shipment, error) {
+	if limits.Weight < 0 || limits.Volume < 0 {
+		return nil, fmt.Errorf("shipment limits must not be negative: %+v", limits)
+	}
+
+	var sizes = make([]int64, 0, len(combination))
+	for size, count := range combination {
+		if count <= 0 {
+			continue
+		}
+
+		var pack = measures[size]
+		if pack.Weight < 0 || pack.Volume < 0 {
+			return nil, fmt.Errorf("measures of pack %d must not be negative: %+v", size, pack)
+		}
+
+		if limits.exceeded(pack) {
+			return nil, fmt.Errorf("%w: pack %d (weight %d, volume %d)", ErrPackOverLimits, size, pack.Weight, pack.Volume)
+		}
+
+		sizes = append(sizes, size)
+	}
+
+	// Largest packs relative to the limits first
+	slices.SortFunc(sizes, func(a, b int64) int {
+		return cmp.Or(
+			cmp.Compare(limits.share(measures[b]), limits.share(measures[a])),
+			cmp.Compare(b, a),
+		)
+	})
+
+	var shipments []Shipment
+	for _, size := range sizes {
+		var (
+			pack      = measures[size]
+			remaining = combination[size]
+			filled    = make([]Shipment, 0, len(shipments)+2)
+		)
+
+		// Fill shipments opened by previous sizes
+		for _, shipment := range shipments {
+			var fit = limits.capacity(shipment.Weight, shipment.Volume, pack)
+			if remaining == 0 || fit == 0 {
+				filled = append(filled, shipment)
+				continue
+			}
+
+			var full = min(remaining/fit, shipment.Count)
+			if full > 0 {
+				filled = append(filled, shipment.with(size, fit, pack, full))
+				remaining -= full * fit
+			}
+
+			var rest = shipment.Count - full
+			if rest > 0 && remaining > 0 {
+				filled = append(filled, shipment.with(size, remaining, pack, 1))
+				remaining, rest = 0, rest-1
+			}
+
+			if rest > 0 {
+				shipment.Count = rest
+				filled = append(filled, shipment)
+			}
+		}
+
+		// Open new shipments for the rest
+		var (
+			empty = Shipment{Packs: map[int64]int64{}}
+			fit   = limits.capacity(0, 0, pack)
+		)
+
+		if full := remaining / fit; full > 0 {
+			filled = append(filled, empty.with(size, fit, pack, full))
+		}
+
+		if rest := remaining % fit; rest > 0 {
+			filled = append(filled, empty.with(size, rest, pack, 1))
+		}
+
+		shipments = filled
+	}
+
+	return shipments, nil
+}
+
+// with returns count copies of the shipment with the number of packs of the size added.
+func (s Shipment) with(size, number int64, pack Measures, count int64) Shipment {
+	var packs = make(map[int64]int64, len(s.Packs)+1)
+	for packSize, packCount := range s.Packs {
+		packs[packSize] = packCount
+	}
+
+	packs[size] += number
+
+	return Shipment{
+		Packs:  packs,
+		Weight: s.Weight + pack.Weight*number,
+		Volume: s.Volume + pack.Volume*number,
+		Count:  count,
+	}
+}
+
+// exceeded reports whether a single pack doesn't fit the limits.
+func (l Limits) exceeded(pack Measures) bool {
+	return (l.Weight > 0 && pack.Weight > l.Weight) || (l.Volume > 0 && pack.Volume > l.Volume)
+}
+
+// share is the largest fraction of a limit taken by the pack.
+func (l Limits) share(pack Measures) float64 {
+	var share float64
+	if l.Weight > 0 {
+		share = float64(pack.Weight) / float64(l.Weight)
+	}
+
+	if l.Volume > 0 {
+		share = max(share, float64(pack.Volume)/float64(l.Volume))
+	}
+
+	return share
+}
+
+// capacity is the number of packs fitting a shipment of the weight and volume.
+func (l Limits) capacity(weight, volume int64, pack Measures) int64 {
+	var fit int64 = math.MaxInt64
+	if l.Weight > 0 && pack.Weight > 0 {
+		fit = min(fit, (l.Weight-weight)/pack.Weight)
+	}
+
+	if l.Volume > 0 && pack.Volume > 0 {
+		fit = min(fit, (l.Volume-volume)/pack.Volume)
+	}
+
+	return fit
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitShipments(t *testing.T) {
+	measures := map[int64]Measures{
+		250:  {Weight: 3, Volume: 2},
+		500:  {Weight: 5, Volume: 4},
+		1000: {Weight: 9, Volume: 8},
+	}
+
+	t.Run("fits single shipment", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{250: 1, 1000: 1}, measures, Limits{Weight: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Shipment{
+			{Packs: map[int64]int64{250: 1, 1000: 1}, Weight: 12, Volume: 10, Count: 1},
+		}, shipments)
+	})
+
+	t.Run("first fit decreasing", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{250: 3, 500: 1, 1000: 3}, measures, Limits{Weight: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Shipment{
+			{Packs: map[int64]int64{1000: 2}, Weight: 18, Volume: 16, Count: 1},
+			{Packs: map[int64]int64{250: 2, 500: 1, 1000: 1}, Weight: 20, Volume: 16, Count: 1},
+			{Packs: map[int64]int64{250: 1}, Weight: 3, Volume: 2, Count: 1},
+		}, shipments)
+	})
+
+	t.Run("volume limit", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{500: 5}, measures, Limits{Weight: 100, Volume: 8})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Shipment{
+			{Packs: map[int64]int64{500: 2}, Weight: 10, Volume: 8, Count: 2},
+			{Packs: map[int64]int64{500: 1}, Weight: 5, Volume: 4, Count: 1},
+		}, shipments)
+	})
+
+	t.Run("huge number of packs", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{250: 1_000_000_001, 1000: 3}, measures, Limits{Weight: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Shipment{
+			{Packs: map[int64]int64{1000: 2}, Weight: 18, Volume: 16, Count: 1},
+			{Packs: map[int64]int64{250: 3, 1000: 1}, Weight: 18, Volume: 14, Count: 1},
+			{Packs: map[int64]int64{250: 6}, Weight: 18, Volume: 12, Count: 166_666_666},
+			{Packs: map[int64]int64{250: 2}, Weight: 6, Volume: 4, Count: 1},
+		}, shipments)
+	})
+
+	t.Run("packs without measures", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{250: 2, 300: 5}, measures, Limits{Weight: 3})
+
+		require.NoError(t, err)
+		assert.Equal(t, []Shipment{
+			{Packs: map[int64]int64{250: 1, 300: 5}, Weight: 3, Volume: 2, Count: 1},
+			{Packs: map[int64]int64{250: 1}, Weight: 3, Volume: 2, Count: 1},
+		}, shipments)
+	})
+
+	t.Run("pack over limits", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{1000: 1}, measures, Limits{Volume: 7})
+
+		assert.ErrorIs(t, err, ErrPackOverLimits)
+		assert.Nil(t, shipments)
+	})
+
+	t.Run("negative limits", func(t *testing.T) {
+		_, err := SplitShipments(map[int64]int64{250: 1}, measures, Limits{Weight: -1})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("empty combination", func(t *testing.T) {
+		shipments, err := SplitShipments(map[int64]int64{}, measures, Limits{Weight: 20})
+
+		require.NoError(t, err)
+		assert.Empty(t, shipments)
+	})
+}
+
+func TestSplitShipments_KeepsPacksWithinLimits(t *testing.T) {
+	var (
+		random   = rand.New(rand.NewSource(7))
+		measures = map[int64]Measures{
+			4: {Weight: 2, Volume: 5},
+			6: {Weight: 3, Volume: 1},
+			9: {Weight: 7, Volume: 3},
+		}
+	)
+
+	for range 200 {
+		var (
+			limits      = Limits{Weight: random.Int63n(20) + 7, Volume: random.Int63n(20) + 5}
+			combination = map[int64]int64{4: random.Int63n(50), 6: random.Int63n(50), 9: random.Int63n(50)}
+			packs       = make(map[int64]int64)
+		)
+
+		shipments, err := SplitShipments(combination, measures, limits)
+		require.NoError(t, err)
+
+		for _, shipment := range shipments {
+			var weight, volume int64
+			for size, count := range shipment.Packs {
+				weight += measures[size].Weight * count
+				volume += measures[size].Volume * count
+				packs[size] += count * shipment.Count
+			}
+
+			assert.Equal(t, weight, shipment.Weight)
+			assert.Equal(t, volume, shipment.Volume)
+			assert.LessOrEqual(t, weight, limits.Weight)
+			assert.LessOrEqual(t, volume, limits.Volume)
+			assert.Positive(t, shipment.Count)
+		}
+
+		for size, count := range combination {
+			assert.Equal(t, count, packs[size], "size %d of %v", size, combination)
+		}
+	}
+}
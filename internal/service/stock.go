@@ -0,0 +1,320 @@
+package service
+
+import (
+	"math"
+	"slices"
+)
+
+// unreachable marks sums that can't be composed in a bounded table.
+const unreachable = math.MaxUint32
+
+// stockItem is a group of packs of the same size taken as a whole.
+type stockItem struct {
+	pack      int64 // Normalized pack size
+	count     int64 // Number of packs in the item
+	cost      int64 // Total cost of packs in the item
+	unlimited bool  // Item can be taken any number of times
+	minimum   bool  // Item is the fewest packs of its size, other items of the size are taken only with it
+}
+
+// boundedTable holds the dynamic programming state of a calculation with limited stock or rules.
+// Limited packs are split into items of 1, 2, 4, ... packs so every item is either
+// taken once or not at all, and one bit per item and sum records whether it was taken.
+type boundedTable struct {
+	items    []stockItem
+	criteria []Criterion // Criteria comparing combinations of the same sum
+	counts   []uint32    // Number of packs of the best combination adding up to the sum, unreachable if impossible
+	costs    []int64     // Cost of the best combination adding up to the sum
+	taken    [][]uint64  // Bitsets of sums which took the item
+}
+
+// stockItems splits available normalized packs into items following the rules. Sizes absent
+// from stock are unlimited, sizes with no packs in stock or rules can't be satisfied are skipped.
+// Packs of a size are taken by steps of its rule, starting with an item of the minimum if it's
+// more than one step. It also returns the largest amount dropping all packs of a size or a step
+// of them takes off a combination and the total amount limited packs can hold,
+// math.MaxInt64 if some are unlimited. Combinations never need sums above the target plus
+// the largest amount, so packs beyond them are left out of items and the total amount.
+// Sizes whose fewest packs overflow any sum are skipped too.
+func stockItems(config tableConfig, stock map[int64]int64, rules map[int64]Rule, target int64) ([]stockItem, int64, int64) {
+	var (
+		items    []stockItem
+		largest  int64
+		capacity int64
+	)
+
+	// steps returns the most steps of the size allowed by its rule and stock, -1 if unlimited
+	var steps = func(pack int64, rule Rule) int64 {
+		var steps = rule.steps()
+		if available, limited := stock[pack]; limited && (steps < 0 || max(available, 0)/rule.step() < steps) {
+			steps = max(available, 0) / rule.step()
+		}
+
+		return steps
+	}
+
+	// usable reports whether some sum can hold packs of the size
+	var usable = func(pack int64, rule Rule) bool {
+		var steps = steps(pack, rule)
+		return (steps < 0 || steps >= rule.leastSteps()) &&
+			saturatedMul(pack, saturatedMul(rule.leastSteps(), rule.step())) < math.MaxInt64
+	}
+
+	for _, pack := range config.packs {
+		if rule := rules[pack]; usable(pack, rule) {
+			largest = max(largest, pack*rule.leastSteps()*rule.step())
+		}
+	}
+
+	var bound = saturatedAdd(target, largest)
+
+	for i, pack := range config.packs {
+		var unitCost int64
+		if config.unitCosts != nil {
+			unitCost = config.unitCosts[i]
+		}
+
+		var (
+			rule  = rules[pack]
+			step  = rule.step()
+			least = rule.leastSteps()
+			steps = steps(pack, rule)
+		)
+
+		if !usable(pack, rule) {
+			continue
+		}
+
+		// Steps of packs above the bound can't be taken into any useful sum
+		if useful := bound / saturatedMul(pack, step); steps > useful {
+			steps = useful
+		}
+
+		var taken int64 // Steps taken by the minimum item
+		if least > 1 {
+			items = append(items, stockItem{pack: pack, count: least * step, cost: unitCost * least * step, minimum: true})
+			taken = least
+		}
+
+		if steps < 0 {
+			items = append(items, stockItem{pack: pack, count: step, cost: unitCost * step, unlimited: true})
+			capacity = math.MaxInt64
+		} else {
+			for count, available := int64(1), steps-taken; available > 0; count *= 2 {
+				count = min(count, available)
+				items = append(items, stockItem{pack: pack, count: count * step, cost: unitCost * count * step})
+				available -= count
+			}
+
+			capacity = saturatedAdd(capacity, saturatedMul(pack, saturatedMul(steps, step)))
+		}
+	}
+
+	return items, largest, capacity
+}
+
+// saturatedAdd returns the sum of non-negative numbers, math.MaxInt64 if it overflows.
+func saturatedAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+
+	return a + b
+}
+
+// saturatedMul returns the product of non-negative numbers, math.MaxInt64 if it overflows.
+func saturatedMul(a, b int64) int64 {
+	if b != 0 && a > math.MaxInt64/b {
+		return math.MaxInt64
+	}
+
+	return a * b
+}
+
+// boundedBytesPerSum returns memory a bounded table of the items takes for every sum.
+func boundedBytesPerSum(items []stockItem) int64 {
+	var bytes = 4 + 8 + int64(len(items)+7)/8 // Number of packs, cost and bits of items
+	if slices.ContainsFunc(items, func(item stockItem) bool { return item.minimum }) {
+		bytes += 4 + 8 // Sums without the size being added
+	}
+
+	return bytes
+}
+
+// newBoundedTable builds the table for all sums from zero up to maxRange.
+func newBoundedTable(items []stockItem, criteria []Criterion, maxRange int64, stop *checkpoint) (*boundedTable, error) {
+	var t = &boundedTable{
+		items:    items,
+		criteria: criteria,
+		counts:   make([]uint32, maxRange+1),
+		costs:    make([]int64, maxRange+1),
+		taken:    make([][]uint64, len(items)),
+	}
+
+	for sum := int64(1); sum <= maxRange; sum++ {
+		t.counts[sum] = unreachable
+	}
+
+	var (
+		opened       int      // Minimum item of the size being added
+		without      []uint32 // Numbers of packs of sums without the size being added, nil if none is
+		costsWithout []int64  // Costs of sums without the size being added
+	)
+
+	for i, item := range items {
+		var weight = item.pack * item.count
+
+		t.taken[i] = make([]uint64, maxRange/64+1)
+
+		// Every item passes all sums at once, so the calculation is checked before every item
+		if err := stop.err(); err != nil {
+			return nil, err
+		}
+
+		// Unlimited items may be added to sums already containing them,
+		// limited ones are added to sums computed without them. Minimum items
+		// make every sum hold the minimum, so other items of the size are added to it.
+		switch {
+		case item.minimum:
+			opened, without, costsWithout = i, slices.Clone(t.counts), slices.Clone(t.costs)
+
+			for sum := maxRange; sum >= 0; sum-- {
+				t.counts[sum], t.costs[sum] = unreachable, 0
+				t.relax(i, sum, sum-weight)
+			}
+		case item.unlimited:
+			for sum := weight; sum <= maxRange; sum++ {
+				t.relax(i, sum, sum-weight)
+			}
+		default:
+			for sum := maxRange; sum >= weight; sum-- {
+				t.relax(i, sum, sum-weight)
+			}
+		}
+
+		// Sums holding the minimum are compared with sums without the size once all its items are added
+		if without != nil && (i == len(items)-1 || items[i+1].pack != item.pack) {
+			t.close(opened, without, costsWithout)
+			without, costsWithout = nil, nil
+		}
+	}
+
+	return t, nil
+}
+
+// close keeps sums holding packs of the size with the minimum item if they are better than
+// the same sums without the size and restores the rest. Kept sums are marked as taken by the item.
+func (t *boundedTable) close(item int, without []uint32, costsWithout []int64) {
+	for sum := range t.counts {
+		var (
+			count = t.counts[sum]
+			cost  = t.costs[sum]
+		)
+
+		t.taken[item][sum/64] &^= 1 << (sum % 64)
+
+		if count != unreachable &&
+			(without[sum] == unreachable || isBetterSum(t.criteria, count, without[sum], cost, costsWithout[sum])) {
+			t.taken[item][sum/64] |= 1 << (sum % 64)
+			continue
+		}
+
+		t.counts[sum], t.costs[sum] = without[sum], costsWithout[sum]
+	}
+}
+
+// relax takes the item into the sum if it makes the sum's combination better.
+func (t *boundedTable) relax(item int, sum, prev int64) {
+	if prev < 0 || t.counts[prev] == unreachable {
+		return
+	}
+
+	var (
+		count = t.counts[prev] + uint32(t.items[item].count)
+		cost  = t.costs[prev] + t.items[item].cost
+	)
+
+	if t.counts[sum] == unreachable || isBetterSum(t.criteria, count, t.counts[sum], cost, t.costs[sum]) {
+		t.counts[sum] = count
+		t.costs[sum] = cost
+		t.taken[item][sum/64] |= 1 << (sum % 64)
+	}
+}
+
+// isTaken reports whether the item was taken to reach the sum.
+func (t *boundedTable) isTaken(item int, sum int64) bool {
+	return t.taken[item][sum/64]&(1<<(sum%64)) != 0
+}
+
+// variant returns the best way to reach the sum or nil if it's unreachable.
+func (t *boundedTable) variant(w window, sum int64) *variant {
+	if t.counts[sum] == unreachable {
+		return nil
+	}
+
+	return &variant{
+		sum: sum,
+		Score: Score{
+			Overshoot: w.overshoot(sum),
+			Packs:     int64(t.counts[sum]),
+			Cost:      t.costs[sum],
+		},
+	}
+}
+
+// combination rebuilds the packs used to reach the sum walking items in reverse order.
+func (t *boundedTable) combination(sum int64) map[int64]int64 {
+	var result = make(map[int64]int64)
+
+	for i := len(t.items) - 1; i >= 0; i-- {
+		var item = t.items[i]
+
+		// Sizes with a minimum item are skipped as a whole unless the sum holds the minimum
+		if i == len(t.items)-1 || t.items[i+1].pack != item.pack {
+			if first := t.firstOfSize(i); t.items[first].minimum && !t.isTaken(first, sum) {
+				i = first
+				continue
+			}
+		}
+
+		if item.minimum {
+			result[item.pack] += item.count
+			sum -= item.pack * item.count
+
+			continue
+		}
+
+		for sum > 0 && t.isTaken(i, sum) {
+			result[item.pack] += item.count
+			sum -= item.pack * item.count
+
+			if !item.unlimited {
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// firstOfSize returns the index of the first item of the same size as the item.
+func (t *boundedTable) firstOfSize(item int) int {
+	for item > 0 && t.items[item-1].pack == t.items[item].pack {
+		item--
+	}
+
+	return item
+}
+
+// normalizeStock converts stock keyed by pack sizes into stock keyed by normalized sizes.
+func normalizeStock(stock map[int64]int64, packs []int64, divisor int64) map[int64]int64 {
+	var normalized = make(map[int64]int64, len(stock))
+
+	for pack, available := range stock {
+		if pack%divisor == 0 && slices.Contains(packs, pack/divisor) {
+			normalized[pack/divisor] += max(available, 0)
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacks_WithStock(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		packs = []int64{250, 500, 1000, 2000, 5000}
+	)
+
+	tests := []struct {
+		name     string
+		stock    map[int64]int64
+		expected map[int64]int64
+		amount   int64
+	}{
+		{
+			name:     "enough stock",
+			stock:    map[int64]int64{5000: 10},
+			amount:   12001,
+			expected: map[int64]int64{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:     "largest pack is out of stock",
+			stock:    map[int64]int64{5000: 0},
+			amount:   12001,
+			expected: map[int64]int64{2000: 6, 250: 1},
+		},
+		{
+			name:     "single largest pack left",
+			stock:    map[int64]int64{5000: 1, 2000: 3},
+			amount:   12001,
+			expected: map[int64]int64{5000: 1, 2000: 3, 1000: 1, 250: 1},
+		},
+		{
+			name:     "small packs are limited",
+			stock:    map[int64]int64{250: 0, 500: 0},
+			amount:   1,
+			expected: map[int64]int64{1000: 1},
+		},
+		{
+			name:     "unknown sizes are ignored",
+			stock:    map[int64]int64{300: 5, 5000: 0},
+			amount:   5000,
+			expected: map[int64]int64{2000: 2, 1000: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NumberOfPacks(ctx, tt.amount, packs, WithStock(tt.stock))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNumberOfPacks_InsufficientStock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not enough packs", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1000, []int64{250, 500}, WithStock(map[int64]int64{250: 1, 500: 1}))
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Nil(t, result)
+	})
+
+	t.Run("everything is out of stock", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1, []int64{250, 500}, WithStock(map[int64]int64{250: 0, 500: 0}))
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Nil(t, result)
+	})
+
+	t.Run("exactly whole stock", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1000, []int64{250, 500}, WithStock(map[int64]int64{250: 2, 500: 1}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{250: 2, 500: 1}, result)
+	})
+}
+
+func TestNumberOfPacks_HugeStock(t *testing.T) {
+	var ctx = context.Background()
+
+	t.Run("counts beyond useful sums", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 100, []int64{5, 7}, WithStock(map[int64]int64{5: 9e18}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{5: 6, 7: 10}, result)
+	})
+
+	t.Run("capacity doesn't overflow", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 10, []int64{3, 5}, WithStock(map[int64]int64{3: 4e18, 5: 1}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{3: 2, 5: 1}, result)
+	})
+}
+
+func TestNumberOfPacks_WithStockMatchesBruteForce(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = rand.New(rand.NewSource(7))
+		packs  = []int64{3, 7, 10}
+	)
+
+	for range 300 {
+		var (
+			amount = random.Int63n(60) + 1
+			stock  = map[int64]int64{
+				3:  random.Int63n(6),
+				7:  random.Int63n(4),
+				10: random.Int63n(3),
+			}
+		)
+
+		expected, ok := bruteForce(amount, packs, constraints{stock: stock})
+
+		result, err := NumberOfPacks(ctx, amount, packs, WithStock(stock))
+		if !ok {
+			assert.ErrorIs(t, err, ErrInsufficientStock, "amount %d, stock %v", amount, stock)
+			continue
+		}
+
+		require.NoError(t, err, "amount %d, stock %v", amount, stock)
+
+		for pack, n := range result {
+			assert.LessOrEqual(t, n, stock[pack], "amount %d, stock %v", amount, stock)
+		}
+
+		assert.Equal(t, expected, scoreOf(amount, result, nil), "amount %d, stock %v", amount, stock)
+	}
+}
+
+func TestStockItems(t *testing.T) {
+	items, largest, capacity := stockItems(defaultConfig(1, 2, 4), map[int64]int64{1: 6, 4: 0}, nil, 100)
+
+	assert.Equal(t, []stockItem{
+		{pack: 1, count: 1},
+		{pack: 1, count: 2},
+		{pack: 1, count: 3},
+		{pack: 2, count: 1, unlimited: true},
+	}, items)
+	assert.Equal(t, int64(2), largest)
+	assert.Greater(t, capacity, int64(1<<62), "unlimited sizes make capacity unlimited")
+}
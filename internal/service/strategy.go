@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownStrategy is returned when a strategy can't be parsed.
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// Criterion is a measure of a combination of packs, lower values are preferred.
+type Criterion int
+
+// Measures of a combination of packs
+const (
+	CriterionOvershoot Criterion = iota // Items shipped above the amount
+	CriterionPacks                      // Number of packs
+	CriterionCost                       // Total cost of packs
+)
+
+// criterionNames maps criteria to their names used in requests.
+var criterionNames = map[Criterion]string{
+	CriterionOvershoot: "overshoot",
+	CriterionPacks:     "packs",
+	CriterionCost:      "cost",
+}
+
+// String returns the name of the criterion.
+func (c Criterion) String() string {
+	if name, ok := criterionNames[c]; ok {
+		return name
+	}
+
+	return "criterion(" + strconv.Itoa(int(c)) + ")"
+}
+
+// ParseCriterion converts a criterion name into a criterion.
+func ParseCriterion(raw string) (Criterion, error) {
+	for criterion, name := range criterionNames {
+		if name == raw {
+			return criterion, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown criterion %q", raw)
+}
+
+// Score measures a combination of packs by every criterion.
+// Cost is zero unless the strategy minimizes it.
+type Score struct {
+	Overshoot int64 // Items shipped above the amount
+	Packs     int64 // Number of packs
+	Cost      int64 // Total cost of packs
+}
+
+// Value returns the score measured by the criterion.
+func (s Score) Value(criterion Criterion) int64 {
+	switch criterion {
+	case CriterionOvershoot:
+		return s.Overshoot
+	case CriterionPacks:
+		return s.Packs
+	case CriterionCost:
+		return s.Cost
+	default:
+		return 0
+	}
+}
+
+// Strategy decides which combination of packs is optimal for an amount.
+// Combinations are compared by criteria in priority order and combinations
+// the strategy doesn't accept are never chosen. Only the best combination
+// of every shipped quantity is checked for acceptance.
+type Strategy interface {
+	// Name returns the name of the strategy as it's set in requests
+	Name() string
+	// Criteria returns measures minimized by the strategy in priority order
+	Criteria() []Criterion
+	// Accept reports whether a combination with the score may be chosen
+	Accept(score Score) bool
+}
+
+// lexicographic is a strategy comparing combinations by criteria in order.
+type lexicographic struct {
+	name     string
+	criteria []Criterion
+}
+
+// Name returns the name of the strategy.
+func (s *lexicographic) Name() string {
+	return s.name
+}
+
+// Criteria returns measures minimized by the strategy.
+func (s *lexicographic) Criteria() []Criterion {
+	return s.criteria
+}
+
+// Accept accepts every combination.
+func (s *lexicographic) Accept(Score) bool {
+	return true
+}
+
+// withinPacks is a strategy looking for the least overshoot among combinations
+// of at most limit packs.
+type withinPacks struct {
+	lexicographic
+
+	limit int64
+}
+
+// Accept accepts combinations of at most limit packs.
+func (s *withinPacks) Accept(score Score) bool {
+	return score.Packs <= s.limit
+}
+
+// LeastOvershoot returns the default strategy preferring the least overshoot,
+// then fewest packs.
+func LeastOvershoot() Strategy {
+	return &lexicographic{
+		name:     "least_overshoot",
+		criteria: []Criterion{CriterionOvershoot, CriterionPacks},
+	}
+}
+
+// FewestPacks returns a strategy preferring fewest packs, then the least overshoot.
+func FewestPacks() Strategy {
+	return &lexicographic{
+		name:     "fewest_packs",
+		criteria: []Criterion{CriterionPacks, CriterionOvershoot},
+	}
+}
+
+// Cheapest returns a strategy preferring the lowest total cost of packs,
+// then the least overshoot and fewest packs.
+func Cheapest() Strategy {
+	return &lexicographic{
+		name:     "cheapest",
+		criteria: []Criterion{CriterionCost, CriterionOvershoot, CriterionPacks},
+	}
+}
+
+// WithinPacks returns a strategy preferring the least overshoot among combinations
+// of at most limit packs, then fewest packs.
+func WithinPacks(limit int64) Strategy {
+	return &withinPacks{
+		lexicographic: lexicographic{
+			name:     "within_packs:" + strconv.FormatInt(limit, 10),
+			criteria: []Criterion{CriterionOvershoot, CriterionPacks},
+		},
+		limit: limit,
+	}
+}
+
+// Lexicographic returns a strategy comparing combinations by the criteria in order.
+func Lexicographic(criteria ...Criterion) Strategy {
+	var names = make([]string, len(criteria))
+	for i, criterion := range criteria {
+		names[i] = criterion.String()
+	}
+
+	return &lexicographic{
+		name:     "lexicographic:" + strings.Join(names, ","),
+		criteria: criteria,
+	}
+}
+
+// ParseStrategy converts a strategy name into a built-in strategy.
+// Supported names are "least_overshoot" (also used for an empty name), "fewest_packs",
+// "cheapest", "within_packs:N" and "lexicographic:criterion,..." with criteria
+// "overshoot", "packs" and "cost".
+func ParseStrategy(raw string) (Strategy, error) {
+	name, argument, _ := strings.Cut(raw, ":")
+
+	switch name {
+	case "", "least_overshoot":
+		return LeastOvershoot(), nil
+	case "fewest_packs":
+		return FewestPacks(), nil
+	case "cheapest":
+		return Cheapest(), nil
+	case "within_packs":
+		limit, err := strconv.ParseInt(argument, 10, 64)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("%w: invalid number of packs %q", ErrUnknownStrategy, argument)
+		}
+
+		return WithinPacks(limit), nil
+	case "lexicographic":
+		var criteria []Criterion
+		for _, raw := range strings.Split(argument, ",") {
+			criterion, err := ParseCriterion(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrUnknownStrategy, err)
+			}
+
+			criteria = append(criteria, criterion)
+		}
+
+		return Lexicographic(criteria...), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, raw)
+	}
+}
+
+// sumCriteria returns criteria comparing combinations of the same sum.
+// Such combinations always have equal overshoot, so it's skipped.
+func sumCriteria(criteria []Criterion) []Criterion {
+	var result = make([]Criterion, 0, len(criteria))
+
+	for _, criterion := range criteria {
+		if criterion != CriterionOvershoot {
+			result = append(result, criterion)
+		}
+	}
+
+	return result
+}
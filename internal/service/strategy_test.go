@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		raw      string
+		name     string
+		criteria []Criterion
+		wantErr  bool
+	}{
+		{raw: "", name: "least_overshoot", criteria: []Criterion{CriterionOvershoot, CriterionPacks}},
+		{raw: "least_overshoot", name: "least_overshoot", criteria: []Criterion{CriterionOvershoot, CriterionPacks}},
+		{raw: "fewest_packs", name: "fewest_packs", criteria: []Criterion{CriterionPacks, CriterionOvershoot}},
+		{raw: "cheapest", name: "cheapest", criteria: []Criterion{CriterionCost, CriterionOvershoot, CriterionPacks}},
+		{raw: "within_packs:3", name: "within_packs:3", criteria: []Criterion{CriterionOvershoot, CriterionPacks}},
+		{raw: "lexicographic:cost, packs", name: "lexicographic:cost,packs", criteria: []Criterion{CriterionCost, CriterionPacks}},
+		{raw: "fastest", wantErr: true},
+		{raw: "within_packs", wantErr: true},
+		{raw: "within_packs:0", wantErr: true},
+		{raw: "lexicographic:", wantErr: true},
+		{raw: "lexicographic:weight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			strategy, err := ParseStrategy(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownStrategy)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, strategy.Name())
+			assert.Equal(t, tt.criteria, strategy.Criteria())
+		})
+	}
+}
+
+func TestNumberOfPacks_Strategies(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		strategy Strategy
+		packs    []int64
+		expected map[int64]int64
+		amount   int64
+	}{
+		{
+			name:     "least overshoot",
+			strategy: LeastOvershoot(),
+			packs:    []int64{3, 5},
+			amount:   9,
+			expected: map[int64]int64{3: 3},
+		},
+		{
+			name:     "fewest packs",
+			strategy: FewestPacks(),
+			packs:    []int64{3, 5},
+			amount:   9,
+			expected: map[int64]int64{5: 2},
+		},
+		{
+			name:     "within packs allows exact fit",
+			strategy: WithinPacks(3),
+			packs:    []int64{3, 5},
+			amount:   9,
+			expected: map[int64]int64{3: 3},
+		},
+		{
+			name:     "within packs overshoots",
+			strategy: WithinPacks(2),
+			packs:    []int64{3, 5},
+			amount:   9,
+			expected: map[int64]int64{5: 2},
+		},
+		{
+			name:     "within packs of huge amount",
+			strategy: WithinPacks(200_000_001),
+			packs:    []int64{250, 500, 1000, 2000, 5000},
+			amount:   1_000_000_000_001,
+			expected: map[int64]int64{5000: 200_000_000, 250: 1},
+		},
+		{
+			name:     "custom priority",
+			strategy: Lexicographic(CriterionPacks),
+			packs:    []int64{3, 5},
+			amount:   9,
+			expected: map[int64]int64{5: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NumberOfPacks(ctx, tt.amount, tt.packs, WithStrategy(tt.strategy))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNumberOfPacks_NoCombination(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unlimited stock", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 11, []int64{3, 5}, WithStrategy(WithinPacks(2)))
+
+		assert.ErrorIs(t, err, ErrNoCombination)
+		assert.Nil(t, result)
+	})
+
+	t.Run("huge amount", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 1_000_000_000_001, []int64{250, 500, 1000, 2000, 5000},
+			WithStrategy(WithinPacks(200_000_000)),
+		)
+
+		assert.ErrorIs(t, err, ErrNoCombination)
+		assert.Nil(t, result)
+	})
+
+	t.Run("limited stock", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 6, []int64{3, 5},
+			WithStrategy(WithinPacks(1)),
+			WithStock(map[int64]int64{5: 1}),
+		)
+
+		assert.ErrorIs(t, err, ErrNoCombination)
+		assert.Nil(t, result)
+	})
+}
+
+func TestNumberOfPacks_Cheapest(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		packs    []int64
+		costs    map[int64]int64
+		stock    map[int64]int64
+		expected map[int64]int64
+		amount   int64
+	}{
+		{
+			name:     "small packs are cheaper",
+			packs:    []int64{250, 500, 1000},
+			costs:    map[int64]int64{250: 1, 500: 3, 1000: 5},
+			amount:   1000,
+			expected: map[int64]int64{250: 4},
+		},
+		{
+			name:     "equal cost prefers fewer packs",
+			packs:    []int64{250, 500},
+			costs:    map[int64]int64{250: 2, 500: 4},
+			amount:   500,
+			expected: map[int64]int64{500: 1},
+		},
+		{
+			name:     "overshoot is cheaper",
+			packs:    []int64{3, 5},
+			costs:    map[int64]int64{3: 10, 5: 1},
+			amount:   6,
+			expected: map[int64]int64{5: 2},
+		},
+		{
+			name:     "sizes without cost are free",
+			packs:    []int64{3, 5},
+			costs:    map[int64]int64{3: 10},
+			amount:   6,
+			expected: map[int64]int64{5: 2},
+		},
+		{
+			name:     "normalized packs",
+			packs:    []int64{300, 500},
+			costs:    map[int64]int64{300: 10, 500: 1},
+			amount:   550,
+			expected: map[int64]int64{500: 2},
+		},
+		{
+			name:     "cheap packs are limited",
+			packs:    []int64{3, 5},
+			costs:    map[int64]int64{3: 10, 5: 1},
+			stock:    map[int64]int64{5: 1},
+			amount:   6,
+			expected: map[int64]int64{3: 1, 5: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NumberOfPacks(ctx, tt.amount, tt.packs,
+				WithStrategy(Cheapest()),
+				WithCosts(tt.costs),
+				WithStock(tt.stock),
+			)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNumberOfPacks_CostsWithDefaultStrategy(t *testing.T) {
+	result, err := NumberOfPacks(context.Background(), 1000, []int64{250, 500, 1000},
+		WithCosts(map[int64]int64{250: 1, 500: 3, 1000: 5}),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{1000: 1}, result, "costs are ignored unless the strategy uses them")
+}
+
+func TestNumberOfPacks_InvalidCosts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("negative cost", func(t *testing.T) {
+		result, err := NumberOfPacks(ctx, 10, []int64{3, 5},
+			WithStrategy(Cheapest()),
+			WithCosts(map[int64]int64{3: -1}),
+		)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestNumberOfPacks_CheapestMatchesBruteForce(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		random = rand.New(rand.NewSource(11))
+		packs  = []int64{4, 6, 9}
+	)
+
+	for range 300 {
+		var (
+			amount = random.Int63n(50) + 1
+			costs  = map[int64]int64{
+				4: random.Int63n(10),
+				6: random.Int63n(10),
+				9: random.Int63n(10),
+			}
+			stock map[int64]int64
+		)
+
+		if random.Intn(2) == 0 {
+			stock = map[int64]int64{4: random.Int63n(8), 9: random.Int63n(4)}
+		}
+
+		expected, ok := bruteForce(amount, packs, constraints{strategy: Cheapest(), stock: stock, costs: costs})
+
+		result, err := NumberOfPacks(ctx, amount, packs,
+			WithStrategy(Cheapest()),
+			WithCosts(costs),
+			WithStock(stock),
+		)
+		if !ok {
+			assert.ErrorIs(t, err, ErrInsufficientStock, "amount %d, costs %v, stock %v", amount, costs, stock)
+			continue
+		}
+
+		require.NoError(t, err, "amount %d, costs %v, stock %v", amount, costs, stock)
+		assert.Equal(t, expected, scoreOf(amount, result, costs), "amount %d, costs %v, stock %v", amount, costs, stock)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned when a calculation is stopped by its context before it's finished.
+var ErrTimeout = errors.New("calculation stopped before it was finished")
+
+// checkPeriod is the number of steps of calculation loops between checks of the context.
+const checkPeriod = 1 << 12
+
+// TimeoutError reports that a calculation was canceled or ran past its deadline.
+// It matches both ErrTimeout and the error of the context.
+type TimeoutError struct {
+	Cause error // Error of the context, context.Canceled or context.DeadlineExceeded
+}
+
+// Error describes why the calculation was stopped.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTimeout, e.Cause)
+}
+
+// Unwrap makes the error match ErrTimeout and the error of the context.
+func (e *TimeoutError) Unwrap() []error {
+	return []error{ErrTimeout, e.Cause}
+}
+
+// WithBudget limits the time a single calculation may take. A calculation running out
+// of the budget fails with TimeoutError matching context.DeadlineExceeded.
+// Zero budget means no limit besides the context of the calculation.
+func WithBudget(budget time.Duration) Option {
+	return func(o *options) {
+		o.budget = budget
+	}
+}
+
+// checkpoint checks the context of a calculation every checkPeriod steps of its loops,
+// so they stop soon after the context is done without paying for a check at every step.
+// Nil checkpoint never stops a calculation.
+type checkpoint struct {
+	ctx   context.Context
+	steps int
+}
+
+// newCheckpoint creates a checkpoint of the context.
+func newCheckpoint(ctx context.Context) *checkpoint {
+	return &checkpoint{ctx: ctx}
+}
+
+// step counts a step of a loop and returns TimeoutError if the context is done
+// when it's time to check it.
+func (c *checkpoint) step() error {
+	if c == nil {
+		return nil
+	}
+
+	c.steps++
+	if c.steps%checkPeriod != 0 {
+		return nil
+	}
+
+	return c.err()
+}
+
+// err returns TimeoutError if the context is done.
+func (c *checkpoint) err() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return &TimeoutError{Cause: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tolerance is an allowed deviation of the shipped quantity from the amount:
+// a number of items plus a percent of the amount.
+type Tolerance struct {
+	Items   int64   // Absolute deviation in items
+	Percent float64 // Deviation in percent of the amount
+}
+
+// items returns the number of items the tolerance allows for the amount.
+func (t Tolerance) items(amount int64) int64 {
+	var percent = float64(amount) * t.Percent / 100
+	if percent >= float64(math.MaxInt64-t.Items) {
+		return math.MaxInt64
+	}
+
+	return t.Items + int64(percent)
+}
+
+// validate reports an error if the tolerance is negative.
+func (t Tolerance) validate() error {
+	if t.Items < 0 || t.Percent < 0 || math.IsNaN(t.Percent) {
+		return fmt.Errorf("tolerance must not be negative: %d items, %g%%", t.Items, t.Percent)
+	}
+
+	return nil
+}
+
+// Fit describes how the shipped quantity relates to the requested amount.
+type Fit string
+
+// Possible fits of the shipped quantity
+const (
+	FitUnder Fit = "under" // Fewer items than requested are shipped
+	FitExact Fit = "exact" // Exactly the requested amount is shipped
+	FitOver  Fit = "over"  // More items than requested are shipped
+)
+
+// FitOf returns how the quantity shipped in the combination relates to the amount.
+func FitOf(amount int64, combination map[int64]int64) Fit {
+	var total int64
+	for pack, count := range combination {
+		total += pack * count
+	}
+
+	switch {
+	case total < amount:
+		return FitUnder
+	case total > amount:
+		return FitOver
+	default:
+		return FitExact
+	}
+}
+
+// window is the range of normalized sums a calculation searches.
+// Items shipped above the amount plus the over tolerance are counted as overshoot.
+type window struct {
+	from    int64 // Smallest sum allowed to ship
+	target  int64 // Smallest sum covering the amount or the limit if it's larger
+	limit   int64 // Largest sum shipped without overshoot
+	slack   int64 // Items above the limit shipped without overshoot, less than the divisor
+	amount  int64 // Requested amount in items, left after bulk packs
+	divisor int64 // Items in a normalized unit
+}
+
+// newWindow returns the window of normalized sums shipping the amount within tolerances.
+func newWindow(amount, divisor int64, under, over Tolerance) window {
+	var (
+		lowest  = max(amount-under.items(amount), 1)
+		highest = amount + min(over.items(amount), (math.MaxInt64-amount)/2)
+	)
+
+	return window{
+		from:    ceilDiv(lowest, divisor),
+		target:  max(ceilDiv(amount, divisor), highest/divisor),
+		limit:   highest / divisor,
+		slack:   highest % divisor,
+		amount:  amount,
+		divisor: divisor,
+	}
+}
+
+// ceilDiv returns a divided by d rounded up without overflow for positive numbers.
+func ceilDiv(a, d int64) int64 {
+	if a%d != 0 {
+		return a/d + 1
+	}
+
+	return a / d
+}
+
+// shifted returns the window with n normalized units set aside.
+func (w window) shifted(n int64) window {
+	return window{
+		from:    w.from - n,
+		target:  w.target - n,
+		limit:   w.limit - n,
+		slack:   w.slack,
+		amount:  w.amount - n*w.divisor,
+		divisor: w.divisor,
+	}
+}
+
+// overshoot returns the overshoot of the sum in items.
+func (w window) overshoot(sum int64) int64 {
+	if sum <= w.limit {
+		return 0
+	}
+
+	return saturatedMul(sum-w.limit, w.divisor) - w.slack
+}
+
+// isCloser reports whether the left sum is closer to the amount than the right one.
+// Shipping more is preferred when both are equally far.
+func (w window) isCloser(left, right int64) bool {
+	var (
+		l = left*w.divisor - w.amount
+		r = right*w.divisor - w.amount
+	)
+
+	if abs(l) != abs(r) {
+		return abs(l) < abs(r)
+	}
+
+	return l > r
+}
+
+// abs returns the absolute value of n.
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
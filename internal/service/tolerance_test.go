@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberOfPacks_Tolerance(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		packs    []int64
+		under    Tolerance
+		over     Tolerance
+		stock    map[int64]int64
+		strategy Strategy
+		expected map[int64]int64
+		amount   int64
+	}{
+		{
+			name:     "no tolerance",
+			packs:    []int64{250, 500, 1000},
+			amount:   1001,
+			expected: map[int64]int64{250: 1, 1000: 1},
+		},
+		{
+			name:     "undershoot in percent saves a pack",
+			packs:    []int64{250, 500, 1000},
+			under:    Tolerance{Percent: 2},
+			amount:   1001,
+			expected: map[int64]int64{1000: 1},
+		},
+		{
+			name:     "undershoot in items saves a pack",
+			packs:    []int64{250, 500, 1000},
+			under:    Tolerance{Items: 1},
+			amount:   1001,
+			expected: map[int64]int64{1000: 1},
+		},
+		{
+			name:     "undershoot beyond tolerance",
+			packs:    []int64{250, 500, 1000},
+			under:    Tolerance{Items: 1},
+			amount:   1002,
+			expected: map[int64]int64{250: 1, 1000: 1},
+		},
+		{
+			name:     "exact fit is preferred within tolerance",
+			packs:    []int64{250, 500, 1000},
+			under:    Tolerance{Percent: 50},
+			over:     Tolerance{Percent: 50},
+			amount:   1000,
+			expected: map[int64]int64{1000: 1},
+		},
+		{
+			name:     "overshoot within tolerance saves packs",
+			packs:    []int64{300, 1000},
+			over:     Tolerance{Percent: 20},
+			amount:   900,
+			expected: map[int64]int64{1000: 1},
+		},
+		{
+			name:     "closest is preferred among equal",
+			packs:    []int64{10},
+			under:    Tolerance{Items: 10},
+			over:     Tolerance{Items: 10},
+			strategy: Lexicographic(CriterionOvershoot),
+			amount:   1003,
+			expected: map[int64]int64{10: 100},
+		},
+		{
+			name:     "overshoot is preferred among equally close",
+			packs:    []int64{10},
+			under:    Tolerance{Items: 10},
+			over:     Tolerance{Items: 10},
+			strategy: Lexicographic(CriterionOvershoot),
+			amount:   1005,
+			expected: map[int64]int64{10: 101},
+		},
+		{
+			name:     "limited stock",
+			packs:    []int64{250, 500, 1000},
+			under:    Tolerance{Items: 1},
+			stock:    map[int64]int64{1000: 0},
+			amount:   1001,
+			expected: map[int64]int64{500: 2},
+		},
+		{
+			name:     "huge amount",
+			packs:    []int64{250, 500, 1000, 2000, 5000},
+			under:    Tolerance{Items: 1},
+			amount:   1_000_000_000_001,
+			expected: map[int64]int64{5000: 200_000_000},
+		},
+		{
+			name:     "overshoot below the divisor counts",
+			packs:    []int64{250, 1250},
+			under:    Tolerance{Items: 1},
+			amount:   1001,
+			expected: map[int64]int64{250: 4},
+		},
+		{
+			name:     "undershoot beats a small overshoot",
+			packs:    []int64{9, 12},
+			under:    Tolerance{Items: 4},
+			amount:   20,
+			expected: map[int64]int64{9: 2},
+		},
+		{
+			name:     "tolerance covering the amount",
+			packs:    []int64{3, 5},
+			under:    Tolerance{Percent: 100},
+			amount:   4,
+			expected: map[int64]int64{3: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts = []Option{WithTolerance(tt.under, tt.over), WithStock(tt.stock)}
+			if tt.strategy != nil {
+				opts = append(opts, WithStrategy(tt.strategy))
+			}
+
+			result, err := NumberOfPacks(ctx, tt.amount, tt.packs, opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNumberOfPacks_ToleranceMatchesBruteForce(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		random  = rand.New(rand.NewSource(8))
+		configs = [][]int64{{250, 1250}, {9, 12}, {6, 10, 15}, {4, 7}, {20, 30, 45}}
+	)
+
+	for range 1000 {
+		var (
+			packs  = configs[random.Intn(len(configs))]
+			amount = random.Int63n(150) + 1
+			under  = Tolerance{Items: random.Int63n(10)}
+			over   = Tolerance{Items: random.Int63n(10)}
+		)
+
+		if random.Intn(2) == 0 {
+			under = Tolerance{Percent: float64(random.Intn(20))}
+		}
+
+		for _, strategy := range []Strategy{LeastOvershoot(), FewestPacks()} {
+			expected, found := bruteForce(amount, packs, constraints{strategy: strategy, under: under, over: over})
+			require.True(t, found)
+
+			result, err := NumberOfPacks(ctx, amount, packs, WithTolerance(under, over), WithStrategy(strategy))
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, toleratedScoreOf(amount+over.items(amount), result, nil),
+				"%s: packs %v, amount %d, under %+v, over %+v: %v", strategy.Name(), packs, amount, under, over, result)
+		}
+	}
+}
+
+func TestNumberOfPacks_InvalidTolerance(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tolerance := range []Tolerance{{Items: -1}, {Percent: -2}} {
+		result, err := NumberOfPacks(ctx, 10, []int64{3, 5}, WithTolerance(tolerance, Tolerance{}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		result, err = NumberOfPacks(ctx, 10, []int64{3, 5}, WithTolerance(Tolerance{}, tolerance))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	}
+}
+
+func TestFitOf(t *testing.T) {
+	assert.Equal(t, FitUnder, FitOf(1001, map[int64]int64{1000: 1}))
+	assert.Equal(t, FitExact, FitOf(1000, map[int64]int64{250: 2, 500: 1}))
+	assert.Equal(t, FitOver, FitOf(999, map[int64]int64{1000: 1}))
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"gorm.io/gorm"
+)
+
+// SaveHierarchy persists a packaging hierarchy along with its levels.
+func (s *store) SaveHierarchy(ctx context.Context, hierarchy *model.Hierarchy) error {
+	return s.db.WithContext(ctx).Create(hierarchy).Error
+}
+
+// GetHierarchyByID retrieves a packaging hierarchy by its unique ID.
+// It includes associated levels ordered from the innermost one through preloading.
+func (s *store) GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error) {
+	var hierarchy model.Hierarchy
+	// Query hierarchy with preloaded levels in order
+	err := s.db.WithContext(ctx).
+		Preload("Levels", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Where("id = ?", id).
+		First(&hierarchy).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &hierarchy, nil
+}
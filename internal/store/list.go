@@ -0,0 +1,256 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+)
+
+// Fields sorting pack listings, ties are broken by pack IDs
+const (
+	SortByCreatedAt   = "created_at"   // Creation time, the default
+	SortByTotalAmount = "total_amount" // Total amount of pack sizes
+)
+
+// Sizes of pages of pack listings
+const (
+	DefaultPageSize = 50  // Number of packs of a page if not set
+	MaxPageSize     = 500 // Largest number of packs of a page
+)
+
+// PackQuery filters, sorts and paginates a pack listing. Zero filters are not applied.
+type PackQuery struct {
+	CreatedAfter   time.Time // Only packs created after the time
+	CreatedBefore  time.Time // Only packs created before the time
+	ContainsSize   int64     // Only packs with an item of the size
+	MinTotalAmount int64     // Only packs with at least the total amount
+	MaxTotalAmount int64     // Only packs with at most the total amount
+	HashPrefix     string    // Only packs with version hashes starting with the prefix
+	SortBy         string    // Sort field, SortByCreatedAt if not set
+	Descending     bool      // Sort in descending order
+	Limit          int       // Most packs of the page, DefaultPageSize if not set, MaxPageSize at most
+	Cursor         string    // Cursor of the page from the previous page, empty for the first page
+}
+
+// PackPage is a page of a pack listing.
+type PackPage struct {
+	Packs      []model.Pack // Packs of the page with their items
+	NextCursor string       // Cursor of the next page, empty on the last page
+}
+
+// packCursor is the position of the last pack of a page. It's bound to the order
+// of the listing, so it can't be used with another one.
+type packCursor struct {
+	SortBy     string `json:"s"`  // Sort field of the listing
+	Descending bool   `json:"d"`  // Sort order of the listing
+	Value      string `json:"v"`  // Sort field value of the last pack
+	ID         string `json:"id"` // ID of the last pack
+}
+
+// normalize returns the query with defaults set and its decoded cursor, nil on the first page.
+// Unknown sort fields and cursors of other listings are rejected with ErrInvalidQuery.
+func (q PackQuery) normalize() (PackQuery, *model.Pack, error) {
+	if q.SortBy == "" {
+		q.SortBy = SortByCreatedAt
+	}
+
+	if q.SortBy != SortByCreatedAt && q.SortBy != SortByTotalAmount {
+		return q, nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, q.SortBy)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+
+	q.Limit = min(q.Limit, MaxPageSize)
+
+	if q.Cursor == "" {
+		return q, nil, nil
+	}
+
+	after, err := q.decodeCursor()
+	if err != nil {
+		return q, nil, fmt.Errorf("%w: invalid cursor", ErrInvalidQuery)
+	}
+
+	return q, after, nil
+}
+
+// decodeCursor returns the last pack of the previous page with its ID and sort field value.
+func (q PackQuery) decodeCursor() (*model.Pack, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor packCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.SortBy != q.SortBy || cursor.Descending != q.Descending {
+		return nil, errors.New("cursor of another order")
+	}
+
+	var pack = model.Pack{ID: cursor.ID}
+	switch q.SortBy {
+	case SortByTotalAmount:
+		pack.TotalAmount, err = strconv.ParseInt(cursor.Value, 10, 64)
+	default:
+		pack.CreatedAt, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+
+	return &pack, err
+}
+
+// encodeCursor returns the cursor of the page following the pack.
+func (q PackQuery) encodeCursor(last model.Pack) string {
+	var cursor = packCursor{SortBy: q.SortBy, Descending: q.Descending, ID: last.ID}
+	switch q.SortBy {
+	case SortByTotalAmount:
+		cursor.Value = strconv.FormatInt(last.TotalAmount, 10)
+	default:
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// sortValue returns the sort field value of the pack as a query argument.
+func (q PackQuery) sortValue(pack *model.Pack) any {
+	if q.SortBy == SortByTotalAmount {
+		return pack.TotalAmount
+	}
+
+	return pack.CreatedAt
+}
+
+// compare orders packs of the listing by the sort field, then by IDs.
+func (q PackQuery) compare(a, b *model.Pack) int {
+	var order int
+	switch q.SortBy {
+	case SortByTotalAmount:
+		order = cmp.Compare(a.TotalAmount, b.TotalAmount)
+	default:
+		order = a.CreatedAt.Compare(b.CreatedAt)
+	}
+
+	order = cmp.Or(order, cmp.Compare(a.ID, b.ID))
+	if q.Descending {
+		return -order
+	}
+
+	return order
+}
+
+// matches reports whether the pack passes filters of the query.
+func (q PackQuery) matches(pack *model.Pack) bool {
+	switch {
+	case !q.CreatedAfter.IsZero() && !pack.CreatedAt.After(q.CreatedAfter),
+		!q.CreatedBefore.IsZero() && !pack.CreatedAt.Before(q.CreatedBefore),
+		q.MinTotalAmount > 0 && pack.TotalAmount < q.MinTotalAmount,
+		q.MaxTotalAmount > 0 && pack.TotalAmount > q.MaxTotalAmount,
+		!strings.HasPrefix(pack.VersionHash, q.HashPrefix):
+		return false
+	case q.ContainsSize > 0:
+		return hasSize(pack, q.ContainsSize)
+	default:
+		return true
+	}
+}
+
+// page returns the page of packs fetched with one pack more than the limit,
+// the extra pack tells there is a next page.
+func (q PackQuery) page(packs []model.Pack) *PackPage {
+	if len(packs) <= q.Limit {
+		return &PackPage{Packs: packs}
+	}
+
+	packs = packs[:q.Limit]
+	return &PackPage{Packs: packs, NextCursor: q.encodeCursor(packs[len(packs)-1])}
+}
+
+// hasSize reports whether the pack has an item of the size.
+func hasSize(pack *model.Pack, size int64) bool {
+	for _, item := range pack.PackItems {
+		if item.Size == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListPacks retrieves a page of active pack configurations passing filters of the query
+// in its order, with associated PackItems through preloading. Pages are fetched by keyset
+// pagination after the cursor, so they stay consistent while packs are created.
+func (s *store) ListPacks(ctx context.Context, query PackQuery) (*PackPage, error) {
+	query, after, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx).Preload("PackItems")
+
+	// Apply filters
+	if !query.CreatedAfter.IsZero() {
+		db = db.Where("created_at > ?", query.CreatedAfter)
+	}
+
+	if !query.CreatedBefore.IsZero() {
+		db = db.Where("created_at < ?", query.CreatedBefore)
+	}
+
+	if query.ContainsSize > 0 {
+		db = db.Where("EXISTS (SELECT 1 FROM pack_items WHERE pack_items.pack_id = packs.id AND pack_items.size = ?)",
+			query.ContainsSize)
+	}
+
+	if query.MinTotalAmount > 0 {
+		db = db.Where("total_amount >= ?", query.MinTotalAmount)
+	}
+
+	if query.MaxTotalAmount > 0 {
+		db = db.Where("total_amount <= ?", query.MaxTotalAmount)
+	}
+
+	if query.HashPrefix != "" {
+		db = db.Where(`version_hash LIKE ? ESCAPE '\'`, escapeLike(query.HashPrefix)+"%")
+	}
+
+	// Sort by the field and IDs, starting after the cursor
+	var direction, operator = "ASC", ">"
+	if query.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	if after != nil {
+		db = db.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", query.SortBy, operator),
+			query.sortValue(after), query.sortValue(after), after.ID)
+	}
+
+	var packs []model.Pack
+	err = db.Order(query.SortBy + " " + direction).
+		Order("id " + direction).
+		Limit(query.Limit + 1).
+		Find(&packs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return query.page(packs), nil
+}
+
+// escapeLike escapes wildcards of LIKE patterns in the value.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
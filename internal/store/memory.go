@@ -0,0 +1,302 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"gorm.io/gorm"
+)
+
+// memoryStore implements the Store interface keeping pack configurations and hierarchies in memory.
+// It follows the same rules as the database store: deleted packs are kept but never returned and
+// version hashes are unique among active packs. Data is lost when the process exits.
+type memoryStore struct {
+	mu          sync.RWMutex      // Guards packs and hierarchies
+	packs       []model.Pack      // Packs in order of creation, including deleted ones
+	hierarchies []model.Hierarchy // Hierarchies in order of creation
+}
+
+// NewMemoryStore creates an empty in-memory store for local development and tests.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+// SavePack persists a single pack configuration in memory.
+func (s *memoryStore) SavePack(_ context.Context, pack *model.Pack) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkPack(pack, nil); err != nil {
+		return err
+	}
+
+	s.insertPack(pack)
+	return nil
+}
+
+// SavePackOnce persists a pack configuration unless an active pack with the same version hash exists,
+// in which case the existing pack is loaded into pack.
+func (s *memoryStore) SavePackOnce(_ context.Context, pack *model.Pack) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing := s.findPack(func(p *model.Pack) bool { return p.VersionHash == pack.VersionHash }); existing != nil {
+		*pack = clonePack(*existing)
+		return false, nil
+	}
+
+	if err := s.checkPack(pack, nil); err != nil {
+		return false, err
+	}
+
+	s.insertPack(pack)
+	return true, nil
+}
+
+// SavePacks persists multiple pack configurations, either all of them or none.
+func (s *memoryStore) SavePacks(_ context.Context, packs ...model.Pack) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range packs {
+		if err := s.checkPack(&packs[i], packs[:i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range packs {
+		s.insertPack(&packs[i])
+	}
+
+	return nil
+}
+
+// GetPackByID retrieves an active pack configuration by its unique ID.
+func (s *memoryStore) GetPackByID(_ context.Context, id string) (*model.Pack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.getPack(func(p *model.Pack) bool { return p.ID == id })
+}
+
+// GetPackByHash retrieves the active pack configuration with the version hash.
+func (s *memoryStore) GetPackByHash(_ context.Context, hash string) (*model.Pack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.getPack(func(p *model.Pack) bool { return p.VersionHash == hash })
+}
+
+// ListPacks returns a page of active pack configurations passing filters of the query in its order.
+func (s *memoryStore) ListPacks(_ context.Context, query PackQuery) (*PackPage, error) {
+	query, after, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var packs = make([]model.Pack, 0)
+	for _, pack := range s.packs {
+		if pack.DeletedAt.Valid || !query.matches(&pack) {
+			continue
+		}
+
+		if after != nil && query.compare(&pack, after) <= 0 {
+			continue
+		}
+
+		packs = append(packs, clonePack(pack))
+	}
+
+	slices.SortFunc(packs, func(a, b model.Pack) int {
+		return query.compare(&a, &b)
+	})
+
+	return query.page(packs[:min(len(packs), query.Limit+1)]), nil
+}
+
+// DeletePack marks an active pack configuration as deleted, doing nothing if there is none.
+func (s *memoryStore) DeletePack(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pack := s.findPack(func(p *model.Pack) bool { return p.ID == id }); pack != nil {
+		pack.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+
+	return nil
+}
+
+// ListDeletedPacks returns deleted pack configurations, the most recently deleted first.
+func (s *memoryStore) ListDeletedPacks(_ context.Context) ([]model.Pack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var packs = make([]model.Pack, 0)
+	for _, pack := range s.packs {
+		if pack.DeletedAt.Valid {
+			packs = append(packs, clonePack(pack))
+		}
+	}
+
+	slices.SortFunc(packs, func(a, b model.Pack) int {
+		return cmp.Or(b.DeletedAt.Time.Compare(a.DeletedAt.Time), cmp.Compare(a.ID, b.ID))
+	})
+
+	return packs, nil
+}
+
+// RestorePack undeletes a deleted pack configuration by its unique ID and returns it.
+// It fails with ErrNotFound if there is no deleted pack with the ID and with ErrConflict
+// if an active pack has the same version hash.
+func (s *memoryStore) RestorePack(_ context.Context, id string) (*model.Pack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := slices.IndexFunc(s.packs, func(p model.Pack) bool { return p.ID == id && p.DeletedAt.Valid })
+	if index < 0 {
+		return nil, ErrNotFound
+	}
+
+	pack := &s.packs[index]
+	if s.findPack(func(p *model.Pack) bool { return p.VersionHash == pack.VersionHash }) != nil {
+		return nil, fmt.Errorf("%w: pack with version hash %s", ErrConflict, pack.VersionHash)
+	}
+
+	pack.DeletedAt = gorm.DeletedAt{}
+	pack.UpdatedAt = time.Now()
+
+	restored := clonePack(*pack)
+	return &restored, nil
+}
+
+// PurgePacks permanently deletes pack configurations deleted before the time along with
+// their items, returning the number of purged packs.
+func (s *memoryStore) PurgePacks(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count = len(s.packs)
+	s.packs = slices.DeleteFunc(s.packs, func(p model.Pack) bool {
+		return p.DeletedAt.Valid && p.DeletedAt.Time.Before(before)
+	})
+
+	return int64(count - len(s.packs)), nil
+}
+
+// SaveHierarchy persists a packaging hierarchy along with its levels.
+func (s *memoryStore) SaveHierarchy(_ context.Context, hierarchy *model.Hierarchy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.hierarchies {
+		if existing.ID == hierarchy.ID {
+			return fmt.Errorf("%w: hierarchy %s", ErrConflict, hierarchy.ID)
+		}
+	}
+
+	setTimestamps(&hierarchy.CreatedAt, &hierarchy.UpdatedAt)
+
+	saved := *hierarchy
+	saved.Levels = slices.Clone(hierarchy.Levels)
+	s.hierarchies = append(s.hierarchies, saved)
+
+	return nil
+}
+
+// GetHierarchyByID retrieves a packaging hierarchy with levels ordered from the innermost one.
+func (s *memoryStore) GetHierarchyByID(_ context.Context, id string) (*model.Hierarchy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, existing := range s.hierarchies {
+		if existing.ID != id || existing.DeletedAt.Valid {
+			continue
+		}
+
+		hierarchy := existing
+		hierarchy.Levels = slices.Clone(existing.Levels)
+		slices.SortStableFunc(hierarchy.Levels, func(a, b model.HierarchyLevel) int {
+			return cmp.Compare(a.Position, b.Position)
+		})
+
+		return &hierarchy, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// HealthCheck reports the store is available while the context is.
+func (s *memoryStore) HealthCheck(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// checkPack returns ErrConflict if the pack's ID is taken by any pack, including pending ones,
+// or its version hash is taken by an active one.
+func (s *memoryStore) checkPack(pack *model.Pack, pending []model.Pack) error {
+	for _, existing := range slices.Concat(s.packs, pending) {
+		if existing.ID == pack.ID {
+			return fmt.Errorf("%w: pack %s", ErrConflict, pack.ID)
+		}
+
+		if existing.VersionHash == pack.VersionHash && !existing.DeletedAt.Valid {
+			return fmt.Errorf("%w: pack with version hash %s", ErrConflict, pack.VersionHash)
+		}
+	}
+
+	return nil
+}
+
+// insertPack sets timestamps of the pack and appends its copy to the store.
+func (s *memoryStore) insertPack(pack *model.Pack) {
+	setTimestamps(&pack.CreatedAt, &pack.UpdatedAt)
+	s.packs = append(s.packs, clonePack(*pack))
+}
+
+// findPack returns the first active pack matching the predicate, nil if there is none.
+func (s *memoryStore) findPack(match func(*model.Pack) bool) *model.Pack {
+	for i := range s.packs {
+		if !s.packs[i].DeletedAt.Valid && match(&s.packs[i]) {
+			return &s.packs[i]
+		}
+	}
+
+	return nil
+}
+
+// getPack returns a copy of the first active pack matching the predicate or ErrNotFound.
+func (s *memoryStore) getPack(match func(*model.Pack) bool) (*model.Pack, error) {
+	pack := s.findPack(match)
+	if pack == nil {
+		return nil, ErrNotFound
+	}
+
+	found := clonePack(*pack)
+	return &found, nil
+}
+
+// clonePack returns a copy of the pack not sharing its items.
+func clonePack(pack model.Pack) model.Pack {
+	pack.PackItems = slices.Clone(pack.PackItems)
+	return pack
+}
+
+// setTimestamps sets unset creation and update timestamps to the current time, as GORM does.
+func setTimestamps(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
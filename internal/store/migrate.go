@@ -0,0 +1,286 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/config"
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds SQL migrations of every supported dialect in migrations/<dialect>,
+// named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockKey identifies the PostgreSQL advisory lock held while migrations run.
+const migrationLockKey int64 = 0x7061636b756c6174 // "packulat"
+
+// migrationFileName matches names of migration files and captures their version, name and direction.
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change of the database schema.
+type Migration struct {
+	Version int64  // Version of the schema after the migration, applied in ascending order
+	Name    string // Short description of the migration
+	Applied bool   // Whether the migration is applied to the database
+	up      string // SQL applying the migration
+	down    string // SQL rolling the migration back
+}
+
+// schemaMigration is a row of the table tracking applied migrations.
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"` // Version of the applied migration
+	Name      string    `gorm:"not null"`                       // Name of the applied migration
+	AppliedAt time.Time `gorm:"not null"`                       // Timestamp when the migration was applied
+}
+
+// TableName returns the name of the table tracking applied migrations.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back versioned SQL migrations embedded into the binary.
+// Applied migrations are tracked in the schema_migrations table and every migration runs
+// in its own transaction. On PostgreSQL an advisory lock is held while migrations run,
+// so several replicas starting at once apply each migration exactly once.
+type Migrator struct {
+	db         *gorm.DB    // GORM database instance
+	migrations []Migration // Known migrations in ascending order of versions
+}
+
+// NewMigrator creates a migrator of the database with migrations of its dialect.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// OpenMigrator connects to the configured database and creates its migrator.
+// The in-memory store has no schema, so it can't be migrated.
+func OpenMigrator(cfg *config.DatabaseConfig) (*Migrator, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(db)
+}
+
+// Up applies all pending migrations in ascending order of versions.
+// It returns migrations applied before an error, if any.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		migrations, err := m.status(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if migration.Applied {
+				continue
+			}
+
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.up).Error; err != nil {
+					return err
+				}
+
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			}); err != nil {
+				return fmt.Errorf("can't apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			migration.Applied = true
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back up to steps latest applied migrations in descending order of versions.
+// It returns migrations rolled back before an error, if any.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		migrations, err := m.status(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range slices.Backward(migrations) {
+			if len(rolledBack) == steps {
+				break
+			}
+
+			if !migration.Applied {
+				continue
+			}
+
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.down).Error; err != nil {
+					return err
+				}
+
+				return tx.Delete(&schemaMigration{Version: migration.Version}).Error
+			}); err != nil {
+				return fmt.Errorf("can't roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			migration.Applied = false
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status returns all known migrations in ascending order of versions marking applied ones.
+func (m *Migrator) Status(ctx context.Context) ([]Migration, error) {
+	var migrations []Migration
+
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		var err error
+		migrations, err = m.status(conn)
+		return err
+	})
+
+	return migrations, err
+}
+
+// Close closes the database connection of the migrator.
+func (m *Migrator) Close() error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// locked runs fn on a single connection holding the migration lock, creating
+// the table tracking applied migrations if it doesn't exist.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == config.DriverPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return fmt.Errorf("can't lock migrations: %w", err)
+			}
+
+			// Unlock even if the context is done, the lock is kept until the connection is closed otherwise
+			defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		}
+
+		if !conn.Migrator().HasTable(&schemaMigration{}) {
+			if err := conn.Migrator().CreateTable(&schemaMigration{}); err != nil {
+				return err
+			}
+		}
+
+		return fn(conn)
+	})
+}
+
+// status returns known migrations marking the ones applied to the database.
+func (m *Migrator) status(conn *gorm.DB) ([]Migration, error) {
+	var versions []int64
+	if err := conn.Model(&schemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+
+	migrations := slices.Clone(m.migrations)
+	for i := range migrations {
+		migrations[i].Applied = slices.Contains(versions, migrations[i].Version)
+	}
+
+	return migrations, nil
+}
+
+// loadMigrations reads embedded migrations of the dialect sorted by versions.
+// Every migration must have both up and down scripts and a unique version.
+func loadMigrations(dialect string) ([]Migration, error) {
+	var dir = path.Join("migrations", dialect)
+
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations of %q databases: %w", dialect, err)
+	}
+
+	var byVersion = make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+
+		script, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, match[2], version)
+		}
+
+		if match[3] == "up" {
+			migration.up = string(script)
+		} else {
+			migration.down = string(script)
+		}
+	}
+
+	var migrations = make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down scripts", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/kliuchnikovv/packulator/internal/config"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "packulator.db")), &gorm.Config{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestLoadMigrations(t *testing.T) {
+	for _, dialect := range []string{config.DriverPostgres, config.DriverSQLite} {
+		migrations, err := loadMigrations(dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+
+		for i, migration := range migrations {
+			assert.Equal(t, int64(i+1), migration.Version, dialect)
+			assert.NotEmpty(t, migration.up, dialect)
+			assert.NotEmpty(t, migration.down, dialect)
+		}
+	}
+
+	_, err := loadMigrations("mysql")
+	assert.Error(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = openTestDB(t)
+	)
+
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.migrations))
+	assert.True(t, db.Migrator().HasTable(&model.Pack{}))
+	assert.True(t, db.Migrator().HasIndex(&model.Pack{}, "idx_packs_active_version_hash"))
+	assert.True(t, db.Migrator().HasIndex(&model.Pack{}, "idx_packs_created_at"))
+
+	// Applied migrations are skipped
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	migrations, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, migration := range migrations {
+		assert.True(t, migration.Applied, migration.Name)
+	}
+
+	// Latest migrations are rolled back first
+	rolledBack, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 1)
+	assert.Equal(t, migrations[len(migrations)-1].Version, rolledBack[0].Version)
+
+	migrations, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, migrations[len(migrations)-1].Applied)
+	assert.True(t, migrations[len(migrations)-2].Applied)
+
+	rolledBack, err = migrator.Down(ctx, len(migrations))
+	require.NoError(t, err)
+	assert.Len(t, rolledBack, len(migrations)-1)
+	assert.False(t, db.Migrator().HasTable(&model.Pack{}))
+
+	migrations, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, migration := range migrations {
+		assert.False(t, migration.Applied, migration.Name)
+	}
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+}
+
+func TestMigrator_AutoMigratedDatabase(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "packulator.db")
+		pack = createTestPackSimple()
+	)
+
+	// Schema created by AutoMigrate before versioned migrations, when pack items had sizes only
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`
+		CREATE TABLE packs (
+			id text PRIMARY KEY,
+			version_hash text NOT NULL,
+			total_amount integer NOT NULL,
+			created_at datetime,
+			updated_at datetime,
+			deleted_at datetime
+		);
+		CREATE INDEX idx_packs_version_hash ON packs (version_hash);
+		CREATE INDEX idx_packs_deleted_at ON packs (deleted_at);
+		CREATE TABLE pack_items (
+			id text PRIMARY KEY,
+			pack_id text NOT NULL,
+			size integer NOT NULL,
+			CONSTRAINT fk_packs_pack_items FOREIGN KEY (pack_id) REFERENCES packs (id)
+		);
+		CREATE INDEX idx_pack_items_pack_id ON pack_items (pack_id);
+		INSERT INTO packs (id, version_hash, total_amount) VALUES ('baseline-pack', 'baseline-hash', 250);
+		INSERT INTO pack_items (id, pack_id, size) VALUES ('baseline-item', 'baseline-pack', 250);
+	`).Error)
+
+	// Databases created by AutoMigrate keep their data under versioned migrations
+	store, err := NewStore(sqlite.Open(path))
+	require.NoError(t, err)
+
+	saved, err := store.GetPackByHash(ctx, "baseline-hash")
+	require.NoError(t, err)
+	require.Len(t, saved.PackItems, 1)
+	assert.Equal(t, int64(250), saved.PackItems[0].Size)
+
+	// Columns added to pack items since are migrated
+	pack.PackItems[0].Cost = 10
+	pack.PackItems[1].Step = 2
+
+	created, err := store.SavePackOnce(ctx, &pack)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	saved, err = store.GetPackByHash(ctx, pack.VersionHash)
+	require.NoError(t, err)
+	assert.Len(t, saved.PackItems, 2)
+}
+
+func TestMigrator_DuplicateVersionHashes(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = openTestDB(t)
+	)
+
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Roll back to the initial schema
+	_, err = migrator.Down(ctx, len(migrator.migrations)-1)
+	require.NoError(t, err)
+
+	// Without the unique index packs may share version hashes, pack items don't matter
+	packs := createTestPacksSimple()
+	require.NoError(t, db.Omit("PackItems").Create(&packs).Error)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Only the oldest pack of the version hash stays active
+	var active []model.Pack
+	require.NoError(t, db.Where("version_hash = ?", packs[0].VersionHash).Find(&active).Error)
+	require.Len(t, active, 1)
+}
+
+func TestOpenMigrator(t *testing.T) {
+	migrator, err := OpenMigrator(&config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "packulator.db"),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, migrator.Close())
+
+	_, err = OpenMigrator(&config.DatabaseConfig{Driver: config.DriverMemory})
+	assert.Error(t, err)
+}
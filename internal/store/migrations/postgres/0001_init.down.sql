@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS hierarchy_levels;
+DROP TABLE IF EXISTS hierarchies;
+DROP TABLE IF EXISTS pack_items;
+DROP TABLE IF EXISTS packs;
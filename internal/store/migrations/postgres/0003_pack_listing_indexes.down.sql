@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pack_items_size;
+DROP INDEX IF EXISTS idx_packs_total_amount;
+DROP INDEX IF EXISTS idx_packs_created_at;
@@ -0,0 +1,2 @@
+-- The columns are part of the initial schema, so they are kept
+SELECT 1;
@@ -0,0 +1,8 @@
+-- Databases created by AutoMigrate before pack item costs, measures and quantity rules were
+-- introduced have pack_items without their columns, the initial migration keeps such tables as they are.
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS cost bigint NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS weight bigint NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS volume bigint NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS min_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS max_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN IF NOT EXISTS step bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,43 @@
+-- Tables of pack configurations and packaging hierarchies. Databases created by AutoMigrate
+-- before versioned migrations already have them, so existing ones are kept as they are.
+CREATE TABLE IF NOT EXISTS packs (
+    id           text PRIMARY KEY,
+    version_hash text NOT NULL,
+    total_amount integer NOT NULL,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_packs_version_hash ON packs (version_hash);
+CREATE INDEX IF NOT EXISTS idx_packs_deleted_at ON packs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS pack_items (
+    id        text PRIMARY KEY,
+    pack_id   text NOT NULL,
+    size      integer NOT NULL,
+    CONSTRAINT fk_packs_pack_items FOREIGN KEY (pack_id) REFERENCES packs (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pack_items_pack_id ON pack_items (pack_id);
+
+CREATE TABLE IF NOT EXISTS hierarchies (
+    id         text PRIMARY KEY,
+    name       text NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_hierarchies_deleted_at ON hierarchies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS hierarchy_levels (
+    id           text PRIMARY KEY,
+    hierarchy_id text NOT NULL,
+    position     integer NOT NULL,
+    name         text NOT NULL,
+    packs_hash   text NOT NULL,
+    CONSTRAINT fk_hierarchies_levels FOREIGN KEY (hierarchy_id) REFERENCES hierarchies (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hierarchy_levels_hierarchy_id ON hierarchy_levels (hierarchy_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_packs_active_version_hash;
+CREATE INDEX IF NOT EXISTS idx_packs_version_hash ON packs (version_hash);
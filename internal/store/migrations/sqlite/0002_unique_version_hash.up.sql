@@ -0,0 +1,11 @@
+-- Keep the oldest active pack of every version hash, so the hash can be unique among active packs
+UPDATE packs SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM packs older
+    WHERE older.version_hash = packs.version_hash
+      AND older.deleted_at IS NULL
+      AND (older.created_at < packs.created_at OR (older.created_at = packs.created_at AND older.id < packs.id))
+);
+
+DROP INDEX IF EXISTS idx_packs_version_hash;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_packs_active_version_hash ON packs (version_hash) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Pack listings are paginated by the sort field and IDs
+CREATE INDEX IF NOT EXISTS idx_packs_created_at ON packs (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_packs_total_amount ON packs (total_amount, id);
+CREATE INDEX IF NOT EXISTS idx_pack_items_size ON pack_items (size);
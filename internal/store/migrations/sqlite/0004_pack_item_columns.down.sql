@@ -0,0 +1,6 @@
+ALTER TABLE pack_items DROP COLUMN step;
+ALTER TABLE pack_items DROP COLUMN max_count;
+ALTER TABLE pack_items DROP COLUMN min_count;
+ALTER TABLE pack_items DROP COLUMN volume;
+ALTER TABLE pack_items DROP COLUMN weight;
+ALTER TABLE pack_items DROP COLUMN cost;
@@ -0,0 +1,8 @@
+-- Costs, measures and quantity rules of pack items. The initial schema holds pack items
+-- as databases created by AutoMigrate before them have, so the columns are added here.
+ALTER TABLE pack_items ADD COLUMN cost integer NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN weight integer NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN volume integer NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN min_count integer NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN max_count integer NOT NULL DEFAULT 0;
+ALTER TABLE pack_items ADD COLUMN step integer NOT NULL DEFAULT 0;
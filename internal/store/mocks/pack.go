@@ -12,8 +12,10 @@ package mock_store
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/kliuchnikovv/packulator/internal/model"
+	store "github.com/kliuchnikovv/packulator/internal/store"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -79,6 +81,45 @@ func (c *MockStoreDeletePackCall) DoAndReturn(f func(context.Context, string) er
 	return c
 }
 
+// GetHierarchyByID mocks base method.
+func (m *MockStore) GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHierarchyByID", ctx, id)
+	ret0, _ := ret[0].(*model.Hierarchy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHierarchyByID indicates an expected call of GetHierarchyByID.
+func (mr *MockStoreMockRecorder) GetHierarchyByID(ctx, id any) *MockStoreGetHierarchyByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHierarchyByID", reflect.TypeOf((*MockStore)(nil).GetHierarchyByID), ctx, id)
+	return &MockStoreGetHierarchyByIDCall{Call: call}
+}
+
+// MockStoreGetHierarchyByIDCall wrap *gomock.Call
+type MockStoreGetHierarchyByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreGetHierarchyByIDCall) Return(arg0 *model.Hierarchy, arg1 error) *MockStoreGetHierarchyByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreGetHierarchyByIDCall) Do(f func(context.Context, string) (*model.Hierarchy, error)) *MockStoreGetHierarchyByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreGetHierarchyByIDCall) DoAndReturn(f func(context.Context, string) (*model.Hierarchy, error)) *MockStoreGetHierarchyByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetPackByHash mocks base method.
 func (m *MockStore) GetPackByHash(ctx context.Context, hash string) (*model.Pack, error) {
 	m.ctrl.T.Helper()
@@ -195,19 +236,58 @@ func (c *MockStoreHealthCheckCall) DoAndReturn(f func(context.Context) error) *M
 	return c
 }
 
-// ListPacks mocks base method.
-func (m *MockStore) ListPacks(ctx context.Context) ([]model.Pack, error) {
+// ListDeletedPacks mocks base method.
+func (m *MockStore) ListDeletedPacks(ctx context.Context) ([]model.Pack, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPacks", ctx)
+	ret := m.ctrl.Call(m, "ListDeletedPacks", ctx)
 	ret0, _ := ret[0].([]model.Pack)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ListDeletedPacks indicates an expected call of ListDeletedPacks.
+func (mr *MockStoreMockRecorder) ListDeletedPacks(ctx any) *MockStoreListDeletedPacksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedPacks", reflect.TypeOf((*MockStore)(nil).ListDeletedPacks), ctx)
+	return &MockStoreListDeletedPacksCall{Call: call}
+}
+
+// MockStoreListDeletedPacksCall wrap *gomock.Call
+type MockStoreListDeletedPacksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreListDeletedPacksCall) Return(arg0 []model.Pack, arg1 error) *MockStoreListDeletedPacksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreListDeletedPacksCall) Do(f func(context.Context) ([]model.Pack, error)) *MockStoreListDeletedPacksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreListDeletedPacksCall) DoAndReturn(f func(context.Context) ([]model.Pack, error)) *MockStoreListDeletedPacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListPacks mocks base method.
+func (m *MockStore) ListPacks(ctx context.Context, query store.PackQuery) (*store.PackPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPacks", ctx, query)
+	ret0, _ := ret[0].(*store.PackPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // ListPacks indicates an expected call of ListPacks.
-func (mr *MockStoreMockRecorder) ListPacks(ctx any) *MockStoreListPacksCall {
+func (mr *MockStoreMockRecorder) ListPacks(ctx, query any) *MockStoreListPacksCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPacks", reflect.TypeOf((*MockStore)(nil).ListPacks), ctx)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPacks", reflect.TypeOf((*MockStore)(nil).ListPacks), ctx, query)
 	return &MockStoreListPacksCall{Call: call}
 }
 
@@ -217,19 +297,135 @@ type MockStoreListPacksCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockStoreListPacksCall) Return(arg0 []model.Pack, arg1 error) *MockStoreListPacksCall {
+func (c *MockStoreListPacksCall) Return(arg0 *store.PackPage, arg1 error) *MockStoreListPacksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreListPacksCall) Do(f func(context.Context, store.PackQuery) (*store.PackPage, error)) *MockStoreListPacksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreListPacksCall) DoAndReturn(f func(context.Context, store.PackQuery) (*store.PackPage, error)) *MockStoreListPacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgePacks mocks base method.
+func (m *MockStore) PurgePacks(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgePacks", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgePacks indicates an expected call of PurgePacks.
+func (mr *MockStoreMockRecorder) PurgePacks(ctx, before any) *MockStorePurgePacksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgePacks", reflect.TypeOf((*MockStore)(nil).PurgePacks), ctx, before)
+	return &MockStorePurgePacksCall{Call: call}
+}
+
+// MockStorePurgePacksCall wrap *gomock.Call
+type MockStorePurgePacksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStorePurgePacksCall) Return(arg0 int64, arg1 error) *MockStorePurgePacksCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockStoreListPacksCall) Do(f func(context.Context) ([]model.Pack, error)) *MockStoreListPacksCall {
+func (c *MockStorePurgePacksCall) Do(f func(context.Context, time.Time) (int64, error)) *MockStorePurgePacksCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockStoreListPacksCall) DoAndReturn(f func(context.Context) ([]model.Pack, error)) *MockStoreListPacksCall {
+func (c *MockStorePurgePacksCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockStorePurgePacksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RestorePack mocks base method.
+func (m *MockStore) RestorePack(ctx context.Context, id string) (*model.Pack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePack", ctx, id)
+	ret0, _ := ret[0].(*model.Pack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestorePack indicates an expected call of RestorePack.
+func (mr *MockStoreMockRecorder) RestorePack(ctx, id any) *MockStoreRestorePackCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePack", reflect.TypeOf((*MockStore)(nil).RestorePack), ctx, id)
+	return &MockStoreRestorePackCall{Call: call}
+}
+
+// MockStoreRestorePackCall wrap *gomock.Call
+type MockStoreRestorePackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreRestorePackCall) Return(arg0 *model.Pack, arg1 error) *MockStoreRestorePackCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreRestorePackCall) Do(f func(context.Context, string) (*model.Pack, error)) *MockStoreRestorePackCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreRestorePackCall) DoAndReturn(f func(context.Context, string) (*model.Pack, error)) *MockStoreRestorePackCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SaveHierarchy mocks base method.
+func (m *MockStore) SaveHierarchy(ctx context.Context, hierarchy *model.Hierarchy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHierarchy", ctx, hierarchy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHierarchy indicates an expected call of SaveHierarchy.
+func (mr *MockStoreMockRecorder) SaveHierarchy(ctx, hierarchy any) *MockStoreSaveHierarchyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHierarchy", reflect.TypeOf((*MockStore)(nil).SaveHierarchy), ctx, hierarchy)
+	return &MockStoreSaveHierarchyCall{Call: call}
+}
+
+// MockStoreSaveHierarchyCall wrap *gomock.Call
+type MockStoreSaveHierarchyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreSaveHierarchyCall) Return(arg0 error) *MockStoreSaveHierarchyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreSaveHierarchyCall) Do(f func(context.Context, *model.Hierarchy) error) *MockStoreSaveHierarchyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreSaveHierarchyCall) DoAndReturn(f func(context.Context, *model.Hierarchy) error) *MockStoreSaveHierarchyCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -272,6 +468,45 @@ func (c *MockStoreSavePackCall) DoAndReturn(f func(context.Context, *model.Pack)
 	return c
 }
 
+// SavePackOnce mocks base method.
+func (m *MockStore) SavePackOnce(ctx context.Context, pack *model.Pack) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePackOnce", ctx, pack)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePackOnce indicates an expected call of SavePackOnce.
+func (mr *MockStoreMockRecorder) SavePackOnce(ctx, pack any) *MockStoreSavePackOnceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePackOnce", reflect.TypeOf((*MockStore)(nil).SavePackOnce), ctx, pack)
+	return &MockStoreSavePackOnceCall{Call: call}
+}
+
+// MockStoreSavePackOnceCall wrap *gomock.Call
+type MockStoreSavePackOnceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreSavePackOnceCall) Return(arg0 bool, arg1 error) *MockStoreSavePackOnceCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreSavePackOnceCall) Do(f func(context.Context, *model.Pack) (bool, error)) *MockStoreSavePackOnceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreSavePackOnceCall) DoAndReturn(f func(context.Context, *model.Pack) (bool, error)) *MockStoreSavePackOnceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SavePacks mocks base method.
 func (m *MockStore) SavePacks(ctx context.Context, packs ...model.Pack) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/glebarez/sqlite"
+	"github.com/kliuchnikovv/packulator/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Open creates a store with the configured database driver: a PostgreSQL server,
+// a SQLite database file or memory. Schemas of databases are migrated by versioned
+// migrations, or by GORM's AutoMigrate in development mode.
+func Open(cfg *config.DatabaseConfig) (Store, error) {
+	if cfg.Driver == config.DriverMemory {
+		return NewMemoryStore(), nil
+	}
+
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		return NewAutoMigratedStore(dialector)
+	}
+
+	return NewStore(dialector)
+}
+
+// newDialector returns the GORM dialector of the configured SQL database.
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return postgres.Open(cfg.DSN()), nil
+	case config.DriverSQLite:
+		return sqlite.Open(cfg.Path), nil
+	case config.DriverMemory:
+		return nil, fmt.Errorf("%q driver has no database schema", cfg.Driver)
+	default:
+		return nil, fmt.Errorf("unknown database driver: %q", cfg.Driver)
+	}
+}
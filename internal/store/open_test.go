@@ -0,0 +1,338 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/config"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		store, err := Open(&config.DatabaseConfig{Driver: config.DriverMemory})
+
+		require.NoError(t, err)
+		assert.IsType(t, &memoryStore{}, store)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		var (
+			ctx = context.Background()
+			cfg = &config.DatabaseConfig{
+				Driver: config.DriverSQLite,
+				Path:   filepath.Join(t.TempDir(), "packulator.db"),
+			}
+			pack = createTestPackSimple()
+		)
+
+		store, err := Open(cfg)
+		require.NoError(t, err)
+		assert.NoError(t, store.HealthCheck(ctx))
+		require.NoError(t, store.SavePack(ctx, &pack))
+
+		// Packs are kept in the file
+		store, err = Open(cfg)
+		require.NoError(t, err)
+
+		_, err = store.GetPackByID(ctx, pack.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown driver", func(t *testing.T) {
+		store, err := Open(&config.DatabaseConfig{Driver: "mysql"})
+
+		assert.Error(t, err)
+		assert.Nil(t, store)
+	})
+}
+
+// TestStores checks that every backend follows the same rules, so the service behaves
+// the same with any of them.
+func TestStores(t *testing.T) {
+	backends := map[string]func(t *testing.T) Store{
+		"memory": func(*testing.T) Store {
+			return NewMemoryStore()
+		},
+		"sqlite": func(t *testing.T) Store {
+			store, err := Open(&config.DatabaseConfig{
+				Driver: config.DriverSQLite,
+				Path:   filepath.Join(t.TempDir(), "packulator.db"),
+			})
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("save and get pack", func(t *testing.T) {
+				store := open(t)
+				pack := createTestPackSimple()
+
+				require.NoError(t, store.SavePack(ctx, &pack))
+				assert.False(t, pack.CreatedAt.IsZero())
+
+				byID, err := store.GetPackByID(ctx, pack.ID)
+				require.NoError(t, err)
+				assert.Equal(t, pack.VersionHash, byID.VersionHash)
+				assert.Len(t, byID.PackItems, 2)
+
+				byHash, err := store.GetPackByHash(ctx, pack.VersionHash)
+				require.NoError(t, err)
+				assert.Equal(t, pack.ID, byHash.ID)
+
+				_, err = store.GetPackByID(ctx, "missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				_, err = store.GetPackByHash(ctx, "missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+			})
+
+			t.Run("deleted packs are hidden", func(t *testing.T) {
+				store := open(t)
+				pack := createTestPackSimple()
+
+				require.NoError(t, store.SavePack(ctx, &pack))
+				require.NoError(t, store.DeletePack(ctx, pack.ID))
+				require.NoError(t, store.DeletePack(ctx, "missing"))
+
+				_, err := store.GetPackByID(ctx, pack.ID)
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				page, err := store.ListPacks(ctx, PackQuery{})
+				require.NoError(t, err)
+				assert.Empty(t, page.Packs)
+			})
+
+			t.Run("save pack once", func(t *testing.T) {
+				store := open(t)
+				pack := createTestPackSimple()
+
+				created, err := store.SavePackOnce(ctx, &pack)
+				require.NoError(t, err)
+				assert.True(t, created)
+
+				duplicate := model.Pack{ID: "pack-2", VersionHash: pack.VersionHash}
+
+				created, err = store.SavePackOnce(ctx, &duplicate)
+				require.NoError(t, err)
+				assert.False(t, created)
+				assert.Equal(t, pack.ID, duplicate.ID)
+				assert.Len(t, duplicate.PackItems, 2)
+
+				// Version hashes of deleted packs can be taken again
+				require.NoError(t, store.DeletePack(ctx, pack.ID))
+
+				duplicate = model.Pack{ID: "pack-2", VersionHash: pack.VersionHash}
+
+				created, err = store.SavePackOnce(ctx, &duplicate)
+				require.NoError(t, err)
+				assert.True(t, created)
+
+				page, err := store.ListPacks(ctx, PackQuery{})
+				require.NoError(t, err)
+				require.Len(t, page.Packs, 1)
+				assert.Equal(t, "pack-2", page.Packs[0].ID)
+			})
+
+			t.Run("save packs atomically", func(t *testing.T) {
+				store := open(t)
+				packs := createTestPacksSimple()
+
+				// Both packs share the version hash, so neither is saved
+				assert.Error(t, store.SavePacks(ctx, packs...))
+
+				page, err := store.ListPacks(ctx, PackQuery{})
+				require.NoError(t, err)
+				assert.Empty(t, page.Packs)
+
+				packs[1].VersionHash = "def456"
+				require.NoError(t, store.SavePacks(ctx, packs...))
+
+				page, err = store.ListPacks(ctx, PackQuery{})
+				require.NoError(t, err)
+				assert.Len(t, page.Packs, 2)
+			})
+
+			t.Run("list packs", func(t *testing.T) {
+				store := open(t)
+				start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+				// Packs created an hour apart with total amounts out of creation order
+				for i, amount := range []int64{500, 250, 1000, 250, 750} {
+					id := fmt.Sprintf("pack-%d", i+1)
+					pack := model.Pack{
+						ID:          id,
+						VersionHash: fmt.Sprintf("%c%d", 'a'+i%2, i),
+						TotalAmount: amount,
+						CreatedAt:   start.Add(time.Duration(i) * time.Hour),
+						PackItems:   []model.PackItem{{ID: "item-" + id, PackID: id, Size: amount}},
+					}
+					require.NoError(t, store.SavePack(ctx, &pack))
+				}
+
+				list := func(query PackQuery) []string {
+					t.Helper()
+
+					var ids []string
+					for {
+						page, err := store.ListPacks(ctx, query)
+						require.NoError(t, err)
+						require.LessOrEqual(t, len(page.Packs), query.Limit)
+
+						for _, pack := range page.Packs {
+							ids = append(ids, pack.ID)
+						}
+
+						if page.NextCursor == "" {
+							return ids
+						}
+
+						query.Cursor = page.NextCursor
+					}
+				}
+
+				assert.Equal(t, []string{"pack-1", "pack-2", "pack-3", "pack-4", "pack-5"}, list(PackQuery{Limit: 2}))
+				assert.Equal(t, []string{"pack-5", "pack-4", "pack-3", "pack-2", "pack-1"},
+					list(PackQuery{Limit: 2, Descending: true}))
+				assert.Equal(t, []string{"pack-2", "pack-4", "pack-1", "pack-5", "pack-3"},
+					list(PackQuery{Limit: 1, SortBy: SortByTotalAmount}))
+				assert.Equal(t, []string{"pack-3", "pack-5", "pack-1", "pack-4", "pack-2"},
+					list(PackQuery{Limit: 3, SortBy: SortByTotalAmount, Descending: true}))
+
+				assert.Equal(t, []string{"pack-3", "pack-4"}, list(PackQuery{
+					Limit:         10,
+					CreatedAfter:  start.Add(time.Hour),
+					CreatedBefore: start.Add(4 * time.Hour),
+				}))
+				assert.Equal(t, []string{"pack-2", "pack-4"}, list(PackQuery{Limit: 10, ContainsSize: 250}))
+				assert.Equal(t, []string{"pack-1", "pack-5"},
+					list(PackQuery{Limit: 10, MinTotalAmount: 500, MaxTotalAmount: 750}))
+				assert.Equal(t, []string{"pack-2", "pack-4"}, list(PackQuery{Limit: 10, HashPrefix: "b"}))
+				assert.Empty(t, list(PackQuery{Limit: 10, HashPrefix: "%"}))
+
+				// Packs deleted between pages are skipped without repeating others
+				page, err := store.ListPacks(ctx, PackQuery{Limit: 2})
+				require.NoError(t, err)
+				require.NoError(t, store.DeletePack(ctx, "pack-3"))
+
+				page, err = store.ListPacks(ctx, PackQuery{Limit: 2, Cursor: page.NextCursor})
+				require.NoError(t, err)
+				require.Len(t, page.Packs, 2)
+				assert.Equal(t, "pack-4", page.Packs[0].ID)
+				assert.Equal(t, "pack-5", page.Packs[1].ID)
+				assert.Len(t, page.Packs[0].PackItems, 1)
+				assert.Empty(t, page.NextCursor)
+
+				// Cursors are bound to the order of the listing
+				_, err = store.ListPacks(ctx, PackQuery{Cursor: "garbage"})
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+
+				first, err := store.ListPacks(ctx, PackQuery{Limit: 1})
+				require.NoError(t, err)
+
+				_, err = store.ListPacks(ctx, PackQuery{SortBy: SortByTotalAmount, Cursor: first.NextCursor})
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+
+				_, err = store.ListPacks(ctx, PackQuery{SortBy: "version_hash"})
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+			})
+
+			t.Run("restore and purge deleted packs", func(t *testing.T) {
+				store := open(t)
+				packs := createTestPacksSimple()
+				packs[1].VersionHash = "def456"
+
+				require.NoError(t, store.SavePacks(ctx, packs...))
+				require.NoError(t, store.DeletePack(ctx, packs[0].ID))
+				time.Sleep(5 * time.Millisecond) // SQLite keeps milliseconds of deletion times
+				require.NoError(t, store.DeletePack(ctx, packs[1].ID))
+
+				deleted, err := store.ListDeletedPacks(ctx)
+				require.NoError(t, err)
+				require.Len(t, deleted, 2)
+				assert.Equal(t, packs[1].ID, deleted[0].ID, "most recently deleted first")
+				assert.True(t, deleted[0].DeletedAt.Valid)
+				assert.Len(t, deleted[0].PackItems, 1)
+
+				// Packs can't be restored over active ones with the same version hash
+				duplicate := model.Pack{ID: "pack-3", VersionHash: packs[0].VersionHash}
+				require.NoError(t, store.SavePack(ctx, &duplicate))
+
+				_, err = store.RestorePack(ctx, packs[0].ID)
+				assert.ErrorIs(t, err, ErrConflict)
+
+				restored, err := store.RestorePack(ctx, packs[1].ID)
+				require.NoError(t, err)
+				assert.Equal(t, packs[1].ID, restored.ID)
+				assert.False(t, restored.DeletedAt.Valid)
+				assert.Len(t, restored.PackItems, 1)
+
+				_, err = store.GetPackByID(ctx, packs[1].ID)
+				assert.NoError(t, err)
+
+				// Only deleted packs are restored
+				_, err = store.RestorePack(ctx, packs[1].ID)
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				_, err = store.RestorePack(ctx, "missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				// Packs deleted after the time are kept
+				purged, err := store.PurgePacks(ctx, time.Now().Add(-time.Hour))
+				require.NoError(t, err)
+				assert.Zero(t, purged)
+
+				purged, err = store.PurgePacks(ctx, time.Now())
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), purged)
+
+				deleted, err = store.ListDeletedPacks(ctx)
+				require.NoError(t, err)
+				assert.Empty(t, deleted)
+
+				_, err = store.RestorePack(ctx, packs[0].ID)
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				// Items are purged with their pack, so the pack can be saved again
+				require.NoError(t, store.DeletePack(ctx, duplicate.ID))
+				require.NoError(t, store.SavePack(ctx, &packs[0]))
+
+				active, err := store.GetPackByID(ctx, packs[0].ID)
+				require.NoError(t, err)
+				assert.Len(t, active.PackItems, 1)
+			})
+
+			t.Run("hierarchy levels in order", func(t *testing.T) {
+				store := open(t)
+				hierarchy := &model.Hierarchy{
+					ID:   "hierarchy-1",
+					Name: "pallets",
+					Levels: []model.HierarchyLevel{
+						{ID: "level-2", HierarchyID: "hierarchy-1", Position: 1, Name: "carton", PacksHash: "cartons"},
+						{ID: "level-1", HierarchyID: "hierarchy-1", Position: 0, Name: "pack", PacksHash: "packs"},
+					},
+				}
+
+				require.NoError(t, store.SaveHierarchy(ctx, hierarchy))
+
+				retrieved, err := store.GetHierarchyByID(ctx, hierarchy.ID)
+				require.NoError(t, err)
+				require.Len(t, retrieved.Levels, 2)
+				assert.Equal(t, "pack", retrieved.Levels[0].Name)
+				assert.Equal(t, "carton", retrieved.Levels[1].Name)
+
+				_, err = store.GetHierarchyByID(ctx, "missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+			})
+		})
+	}
+}
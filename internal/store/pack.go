@@ -1,36 +1,55 @@
 // Package store provides database access layer for the Packulator application.
-// It implements data persistence operations using GORM ORM with PostgreSQL.
+// It implements data persistence operations using GORM ORM with PostgreSQL or SQLite,
+// and an in-memory store for local development and tests.
 package store
 
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/kliuchnikovv/packulator/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockgen -source=pack.go -destination=mocks/pack.go -typed
 
 // Common store errors
 var (
-	ErrNotFound = errors.New("not found") // Returned when a requested entity is not found
+	ErrNotFound     = errors.New("not found")      // Returned when a requested entity is not found
+	ErrConflict     = errors.New("already exists") // Returned when an entity conflicts with a saved one
+	ErrInvalidQuery = errors.New("invalid query")  // Returned when a listing query can't be applied
 )
 
 // Store defines the interface for database operations on pack configurations.
 type Store interface {
 	// SavePack persists a single pack configuration to the database
 	SavePack(ctx context.Context, pack *model.Pack) error
+	// SavePackOnce persists a pack configuration unless one with the same version hash exists,
+	// loading the existing one instead, and reports whether the pack was created
+	SavePackOnce(ctx context.Context, pack *model.Pack) (bool, error)
 	// SavePacks persists multiple pack configurations in a single transaction
 	SavePacks(ctx context.Context, packs ...model.Pack) error
 	// GetPackByID retrieves a pack configuration by its unique ID
 	GetPackByID(ctx context.Context, id string) (*model.Pack, error)
 	// GetPackByHash retrieves a pack configuration by its version hash
 	GetPackByHash(ctx context.Context, hash string) (*model.Pack, error)
-	// ListPacks returns all pack configurations in the database
-	ListPacks(ctx context.Context) ([]model.Pack, error)
+	// ListPacks returns a page of pack configurations filtered and sorted by the query
+	ListPacks(ctx context.Context, query PackQuery) (*PackPage, error)
 	// DeletePack removes a pack configuration by its unique ID (soft delete)
 	DeletePack(ctx context.Context, id string) error
+	// ListDeletedPacks returns soft-deleted pack configurations, the most recently deleted first
+	ListDeletedPacks(ctx context.Context) ([]model.Pack, error)
+	// RestorePack undeletes a soft-deleted pack configuration by its unique ID and returns it
+	RestorePack(ctx context.Context, id string) (*model.Pack, error)
+	// PurgePacks permanently deletes pack configurations soft-deleted before the time
+	// with their items, returning the number of purged packs
+	PurgePacks(ctx context.Context, before time.Time) (int64, error)
+	// SaveHierarchy persists a packaging hierarchy with its levels
+	SaveHierarchy(ctx context.Context, hierarchy *model.Hierarchy) error
+	// GetHierarchyByID retrieves a packaging hierarchy with levels ordered from the innermost one
+	GetHierarchyByID(ctx context.Context, id string) (*model.Hierarchy, error)
 	// HealthCheck verifies database connectivity
 	HealthCheck(ctx context.Context) error
 }
@@ -41,7 +60,7 @@ type store struct {
 }
 
 // NewStore creates a new store instance with the given GORM dialector.
-// It automatically runs database migrations for Pack and PackItem models.
+// It applies pending versioned migrations, see Migrator, before the store is used.
 func NewStore(dialector gorm.Dialector) (Store, error) {
 	// Initialize GORM database connection
 	db, err := gorm.Open(dialector, &gorm.Config{})
@@ -49,8 +68,38 @@ func NewStore(dialector gorm.Dialector) (Store, error) {
 		return nil, err
 	}
 
-	// Run automatic database migrations for pack models
-	if err := db.AutoMigrate(&model.Pack{}, &model.PackItem{}); err != nil {
+	// Apply pending migrations, waiting for other replicas applying them
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return &store{db: db}, nil
+}
+
+// NewAutoMigratedStore creates a new store instance with the given GORM dialector
+// migrating its schema by GORM's AutoMigrate instead of versioned migrations.
+// It's meant for development only: AutoMigrate can't drop or rename columns and
+// races when several replicas start at once. SQLite databases it creates already have
+// columns versioned migrations add, so they can't be switched to versioned migrations later.
+func NewAutoMigratedStore(dialector gorm.Dialector) (Store, error) {
+	// Initialize GORM database connection
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Run automatic database migrations for pack and hierarchy models
+	if err := db.AutoMigrate(
+		&model.Pack{},
+		&model.PackItem{},
+		&model.Hierarchy{},
+		&model.HierarchyLevel{},
+	); err != nil {
 		return nil, err
 	}
 
@@ -62,6 +111,48 @@ func (s *store) SavePack(ctx context.Context, pack *model.Pack) error {
 	return s.db.WithContext(ctx).Create(pack).Error
 }
 
+// SavePackOnce persists a pack configuration with its items unless an active pack with the same
+// version hash exists, in which case the existing pack with its items is loaded into pack.
+// Concurrent saves of the same configuration are resolved by the unique index of version hashes,
+// so exactly one of them creates the pack.
+func (s *store) SavePackOnce(ctx context.Context, pack *model.Pack) (bool, error) {
+	var created bool
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Insert the pack alone, doing nothing if its version hash is taken
+		result := tx.Omit("PackItems").Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "version_hash"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).Create(pack)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			var existing model.Pack
+			if err := tx.Preload("PackItems").Where("version_hash = ?", pack.VersionHash).First(&existing).Error; err != nil {
+				return err
+			}
+
+			*pack = existing
+			return nil
+		}
+
+		created = true
+		if len(pack.PackItems) == 0 {
+			return nil
+		}
+
+		return tx.Create(&pack.PackItems).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
 // SavePacks persists multiple pack configurations in a single database transaction.
 // This ensures atomicity - either all packs are saved or none are.
 func (s *store) SavePacks(ctx context.Context, packs ...model.Pack) error {
@@ -106,15 +197,6 @@ func (s *store) GetPackByHash(ctx context.Context, hash string) (*model.Pack, er
 	return &pack, nil
 }
 
-// ListPacks retrieves all pack configurations from the database.
-// It includes associated PackItems for each pack through preloading.
-func (s *store) ListPacks(ctx context.Context) ([]model.Pack, error) {
-	var packs []model.Pack
-	// Query all packs with preloaded pack items
-	err := s.db.WithContext(ctx).Preload("PackItems").Find(&packs).Error
-	return packs, err
-}
-
 // DeletePack performs a soft delete on a pack configuration by its unique ID.
 // GORM's soft delete sets the DeletedAt timestamp instead of actually removing the record.
 func (s *store) DeletePack(ctx context.Context, id string) error {
@@ -5,8 +5,10 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/kliuchnikovv/packulator/internal/config"
 	"github.com/kliuchnikovv/packulator/internal/model"
@@ -59,6 +61,9 @@ func createTestPackForIntegration() *model.Pack {
 				ID:     "test-item-2",
 				PackID: "test-pack-integration",
 				Size:   500,
+				Weight: 5,
+				Volume: 4,
+				Step:   2,
 			},
 		},
 	}
@@ -76,6 +81,27 @@ func TestStoreIntegration_HealthCheck(t *testing.T) {
 	assert.NoError(t, err, "Health check should pass with valid database connection")
 }
 
+func TestStoreIntegration_ConcurrentMigrations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cfg := getTestDatabaseConfig()
+
+	// Replicas starting at once wait for each other's migrations
+	var errs = make(chan error, 4)
+	for range cap(errs) {
+		go func() {
+			_, err := NewStore(postgres.Open(cfg.DSN()))
+			errs <- err
+		}()
+	}
+
+	for range cap(errs) {
+		assert.NoError(t, <-errs)
+	}
+}
+
 func TestStoreIntegration_SaveAndGetPack(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -99,6 +125,16 @@ func TestStoreIntegration_SaveAndGetPack(t *testing.T) {
 	assert.Equal(t, pack.VersionHash, retrievedPack.VersionHash)
 	assert.Equal(t, pack.TotalAmount, retrievedPack.TotalAmount)
 	assert.Len(t, retrievedPack.PackItems, 2)
+	var expected = make(map[int64]model.PackItem, len(pack.PackItems))
+	for _, item := range pack.PackItems {
+		expected[item.Size] = item
+	}
+
+	for _, item := range retrievedPack.PackItems {
+		assert.Equal(t, expected[item.Size].Weight, item.Weight, "weight of pack %d", item.Size)
+		assert.Equal(t, expected[item.Size].Volume, item.Volume, "volume of pack %d", item.Size)
+		assert.Equal(t, expected[item.Size].Step, item.Step, "step of pack %d", item.Size)
+	}
 
 	// Cleanup
 	err = store.DeletePack(ctx, pack.ID)
@@ -129,7 +165,7 @@ func TestStoreIntegration_SavePacks(t *testing.T) {
 
 	pack2 := model.Pack{
 		ID:          "batch-pack-2",
-		VersionHash: versionHash,
+		VersionHash: versionHash + "-2",
 		TotalAmount: 500,
 		PackItems: []model.PackItem{
 			{
@@ -144,7 +180,7 @@ func TestStoreIntegration_SavePacks(t *testing.T) {
 	err := store.SavePacks(ctx, pack1, pack2)
 	require.NoError(t, err, "Should save packs in batch successfully")
 
-	// Get pack by version hash (should find the first one)
+	// Get pack by version hash
 	retrievedPack, err := store.GetPackByHash(ctx, versionHash)
 	require.NoError(t, err, "Should retrieve pack by hash successfully")
 
@@ -159,6 +195,50 @@ func TestStoreIntegration_SavePacks(t *testing.T) {
 	require.NoError(t, err, "Should delete pack2 successfully")
 }
 
+func TestStoreIntegration_SavePackOnce(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	pack := createTestPackForIntegration()
+	pack.ID = "once-pack-1"
+	pack.VersionHash = "once-test-hash"
+	for i := range pack.PackItems {
+		pack.PackItems[i].ID = fmt.Sprintf("once-item-1-%d", i)
+		pack.PackItems[i].PackID = pack.ID
+	}
+
+	created, err := store.SavePackOnce(ctx, pack)
+	require.NoError(t, err, "Should save pack successfully")
+	assert.True(t, created)
+
+	// Same version hash loads the existing pack
+	duplicate := &model.Pack{ID: "once-pack-2", VersionHash: pack.VersionHash, TotalAmount: 1}
+
+	created, err = store.SavePackOnce(ctx, duplicate)
+	require.NoError(t, err, "Should load existing pack successfully")
+	assert.False(t, created)
+	assert.Equal(t, pack.ID, duplicate.ID)
+	assert.Equal(t, pack.TotalAmount, duplicate.TotalAmount)
+	assert.Len(t, duplicate.PackItems, 2)
+
+	// Deleted packs don't take their version hashes
+	err = store.DeletePack(ctx, pack.ID)
+	require.NoError(t, err, "Should delete pack successfully")
+
+	duplicate = &model.Pack{ID: "once-pack-2", VersionHash: pack.VersionHash, TotalAmount: 1}
+
+	created, err = store.SavePackOnce(ctx, duplicate)
+	require.NoError(t, err, "Should save pack successfully")
+	assert.True(t, created)
+
+	err = store.DeletePack(ctx, duplicate.ID)
+	require.NoError(t, err, "Should delete pack successfully")
+}
+
 func TestStoreIntegration_ListPacks(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -175,15 +255,15 @@ func TestStoreIntegration_ListPacks(t *testing.T) {
 	require.NoError(t, err, "Should save pack successfully")
 
 	// List all packs
-	packs, err := store.ListPacks(ctx)
+	page, err := store.ListPacks(ctx, PackQuery{Limit: MaxPageSize})
 	require.NoError(t, err, "Should list packs successfully")
 
 	// Should have at least our test pack
-	assert.GreaterOrEqual(t, len(packs), 1)
+	assert.GreaterOrEqual(t, len(page.Packs), 1)
 
 	// Find our test pack in the list
 	found := false
-	for _, p := range packs {
+	for _, p := range page.Packs {
 		if p.ID == pack.ID {
 			found = true
 			assert.Equal(t, pack.VersionHash, p.VersionHash)
@@ -198,6 +278,48 @@ func TestStoreIntegration_ListPacks(t *testing.T) {
 	require.NoError(t, err, "Should delete pack successfully")
 }
 
+func TestStoreIntegration_RestoreAndPurgePacks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	pack := createTestPackForIntegration()
+	pack.ID = "purge-test-pack"
+	pack.VersionHash = "purge-test-hash"
+	for i := range pack.PackItems {
+		pack.PackItems[i].ID = fmt.Sprintf("purge-test-item-%d", i)
+		pack.PackItems[i].PackID = pack.ID
+	}
+
+	require.NoError(t, store.SavePack(ctx, pack))
+	require.NoError(t, store.DeletePack(ctx, pack.ID))
+
+	restored, err := store.RestorePack(ctx, pack.ID)
+	require.NoError(t, err, "Should restore deleted pack")
+	assert.Len(t, restored.PackItems, 2)
+
+	require.NoError(t, store.DeletePack(ctx, pack.ID))
+
+	purged, err := store.PurgePacks(ctx, time.Now())
+	require.NoError(t, err, "Should purge deleted packs")
+	assert.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = store.RestorePack(ctx, pack.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Items are purged with the pack, so it can be saved again
+	pack.CreatedAt, pack.UpdatedAt = time.Time{}, time.Time{}
+	require.NoError(t, store.SavePack(ctx, pack), "Should save purged pack again")
+
+	// Cleanup
+	require.NoError(t, store.DeletePack(ctx, pack.ID))
+	_, err = store.PurgePacks(ctx, time.Now())
+	require.NoError(t, err)
+}
+
 func TestStoreIntegration_GetPackByID_NotFound(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -244,3 +366,47 @@ func TestStoreIntegration_DeletePack_NonExistent(t *testing.T) {
 	// GORM delete doesn't return error for non-existent records
 	assert.NoError(t, err, "Delete should not return error even for non-existent pack")
 }
+
+func TestStoreIntegration_SaveAndGetHierarchy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	// Save levels out of order to check they are loaded from the innermost one
+	hierarchy := &model.Hierarchy{
+		ID:   "test-hierarchy-integration",
+		Name: "pallets",
+		Levels: []model.HierarchyLevel{
+			{ID: "test-level-2", HierarchyID: "test-hierarchy-integration", Position: 1, Name: "carton", PacksHash: "cartons"},
+			{ID: "test-level-1", HierarchyID: "test-hierarchy-integration", Position: 0, Name: "pack", PacksHash: "packs"},
+		},
+	}
+
+	err := store.SaveHierarchy(ctx, hierarchy)
+	require.NoError(t, err, "Should save hierarchy successfully")
+
+	retrieved, err := store.GetHierarchyByID(ctx, hierarchy.ID)
+	require.NoError(t, err, "Should retrieve hierarchy successfully")
+
+	assert.Equal(t, hierarchy.Name, retrieved.Name)
+	require.Len(t, retrieved.Levels, 2)
+	assert.Equal(t, "pack", retrieved.Levels[0].Name)
+	assert.Equal(t, "carton", retrieved.Levels[1].Name)
+}
+
+func TestStoreIntegration_GetHierarchyByID_NotFound(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	hierarchy, err := store.GetHierarchyByID(ctx, "non-existent-hierarchy")
+
+	assert.ErrorIs(t, err, ErrNotFound, "Should return ErrNotFound specifically")
+	assert.Nil(t, hierarchy, "Hierarchy should be nil")
+}
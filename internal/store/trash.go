@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kliuchnikovv/packulator/internal/config"
+	"github.com/kliuchnikovv/packulator/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchSize is the number of packs permanently deleted by a single transaction.
+const purgeBatchSize = 500
+
+// ListDeletedPacks retrieves soft-deleted pack configurations with associated PackItems,
+// the most recently deleted first.
+func (s *store) ListDeletedPacks(ctx context.Context) ([]model.Pack, error) {
+	var packs []model.Pack
+	err := s.db.WithContext(ctx).Unscoped().
+		Preload("PackItems").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Order("id").
+		Find(&packs).Error
+	if err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+// RestorePack undeletes a soft-deleted pack configuration by its unique ID and returns it.
+// It fails with ErrNotFound if there is no deleted pack with the ID and with ErrConflict
+// if an active pack has the same version hash.
+func (s *store) RestorePack(ctx context.Context, id string) (*model.Pack, error) {
+	var pack model.Pack
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&pack).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Version hashes are unique among active packs
+		var active int64
+		if err := tx.Model(&model.Pack{}).Where("version_hash = ?", pack.VersionHash).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return fmt.Errorf("%w: pack with version hash %s", ErrConflict, pack.VersionHash)
+		}
+
+		// Packs purged meanwhile are not restored
+		restored := tx.Unscoped().Model(&pack).Where("deleted_at IS NOT NULL").Update("deleted_at", nil)
+		if restored.Error != nil {
+			return restored.Error
+		}
+		if restored.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Preload("PackItems").Where("id = ?", id).First(&pack).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pack, nil
+}
+
+// PurgePacks permanently deletes pack configurations soft-deleted before the time along
+// with their PackItems, in batches of purgeBatchSize packs each in its own transaction.
+// It returns the number of purged packs, including ones purged before an error.
+func (s *store) PurgePacks(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	for {
+		var ids []string
+
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			query := tx.Unscoped().Model(&model.Pack{}).Where("deleted_at < ?", before).Limit(purgeBatchSize)
+
+			// Lock packs of the batch, so they can't be restored while they are purged
+			if tx.Dialector.Name() == config.DriverPostgres {
+				query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+			}
+
+			if err := query.Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+				return err
+			}
+
+			if err := tx.Where("pack_id IN ?", ids).Delete(&model.PackItem{}).Error; err != nil {
+				return err
+			}
+
+			return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Pack{}).Error
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		purged += int64(len(ids))
+	}
+}